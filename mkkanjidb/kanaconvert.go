@@ -12,6 +12,10 @@ var kanaMap = map[string]string{
 	"ゥ": "ぅ",
 	"ェ": "ぇ",
 	"ォ": "ぉ",
+	"ッ": "っ",
+	"ャ": "ゃ",
+	"ュ": "ゅ",
+	"ョ": "ょ",
 	"カ": "か",
 	"キ": "き",
 	"ク": "く",
@@ -96,6 +100,9 @@ var kanaVowelEndingMap = map[string]string{
 	"ゥ": "う",
 	"ェ": "え",
 	"ォ": "お",
+	"ャ": "あ",
+	"ュ": "う",
+	"ョ": "お",
 	"カ": "あ",
 	"キ": "い",
 	"ク": "う",
@@ -9,6 +9,7 @@ import (
 	"log"
 	"os"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/semog/go-sqldb"
@@ -24,7 +25,12 @@ const kanjidictFileName = "kanjidic2.xml"
 const ptsFactor = 3.0 // maxPts / maxLimit
 const maxJLPT = 6
 
+// The frequency ranks of the first and second halves of the news, ichi, spec and gai lists.
+const freqRankFirstHalf = 24
+const freqRankSecondHalf = 48
+
 type kmap map[string]int
+type lmap map[string]misc
 
 var db *sqldb.SQLDb
 var insertcount = 0
@@ -41,18 +47,18 @@ func main() {
 		return
 	}
 	log.Printf("Loading kanji points map...")
-	kptsmap, err := getKanjiPtsMap()
+	kptsmap, klevelmap, err := getKanjiPtsMap()
 	if err != nil {
 		log.Printf("error: %v", err)
 		return
 	}
 	log.Printf("Creating kanji database...")
-	if err = createKanjiDb(dict, kptsmap); err != nil {
+	if err = createKanjiDb(dict, kptsmap, klevelmap); err != nil {
 		log.Printf("ERROR creating database: %v\n", err)
 	}
 }
 
-func createKanjiDb(dict *jmdict, kptsmap kmap) error {
+func createKanjiDb(dict *jmdict, kptsmap kmap, klevelmap lmap) error {
 	var err error
 	db, err = sqldb.OpenDb(dbFilename)
 	if err != nil {
@@ -60,7 +66,22 @@ func createKanjiDb(dict *jmdict, kptsmap kmap) error {
 	}
 	db.DropTable("words")
 	db.DropTable("kanjipoints")
-	err = db.CreateTable("words (seq TEXT, kanji TEXT PRIMARY KEY, kana TEXT, points INT)")
+	db.DropTable("wordtags")
+	db.DropTable("kanjilevels")
+	db.DropTable("readings")
+	err = db.CreateTable("words (seq TEXT, kanji TEXT PRIMARY KEY, kana TEXT, reading TEXT, points INT, freq INT)")
+	if err != nil {
+		return err
+	}
+	err = db.CreateTable("wordtags (kanji TEXT, tag TEXT, kind TEXT, PRIMARY KEY (kanji, tag))")
+	if err != nil {
+		return err
+	}
+	err = db.CreateIndex("wordtags_tag_idx ON wordtags (tag)")
+	if err != nil {
+		return err
+	}
+	err = db.CreateTable("readings (reading TEXT, kanji TEXT, PRIMARY KEY (reading, kanji))")
 	if err != nil {
 		return err
 	}
@@ -68,10 +89,18 @@ func createKanjiDb(dict *jmdict, kptsmap kmap) error {
 	if err != nil {
 		return err
 	}
+	err = db.CreateTable("kanjilevels (kanji TEXT PRIMARY KEY, jlpt INT, grade INT)")
+	if err != nil {
+		return err
+	}
 	err = insertKanjiPoints(kptsmap)
 	if err != nil {
 		return err
 	}
+	err = insertKanjiLevels(klevelmap)
+	if err != nil {
+		return err
+	}
 	return insertWords(dict, kptsmap)
 }
 
@@ -101,14 +130,52 @@ func insertKanjiPoints(kptsmap kmap) error {
 	return db.CommitTrans()
 }
 
+func insertKanjiLevels(klevelmap lmap) error {
+	var err error
+
+	log.Printf("Inserting kanji levels...")
+	// Prepare the statement and use a transaction for massive speed increase.
+	insertStmt, err := db.Prepare("INSERT INTO kanjilevels (kanji, jlpt, grade) VALUES (:KJ, :JL, :GR)")
+	if err != nil {
+		return err
+	}
+	defer insertStmt.Close()
+	// Optimize the database insertion
+	err = db.BeginTrans()
+	if err != nil {
+		return err
+	}
+
+	for k, l := range klevelmap {
+		_, err = insertStmt.Exec(sql.Named("KJ", &k), sql.Named("JL", &l.JLPT), sql.Named("GR", &l.Grade))
+		if err != nil {
+			db.RollbackTrans()
+			return err
+		}
+	}
+	return db.CommitTrans()
+}
+
 func insertWords(dict *jmdict, kptsmap kmap) error {
 	log.Printf("Inserting words...")
 	// Prepare the statement and use a transaction for massive speed increase.
-	insertStmt, err := db.Prepare("INSERT INTO words (seq, kanji, kana, points) VALUES (:SQ, :KJ, :KN, :SC)")
+	insertStmt, err := db.Prepare("INSERT INTO words (seq, kanji, kana, reading, points, freq) VALUES (:SQ, :KJ, :KN, :RD, :SC, :FQ)")
 	if err != nil {
 		return err
 	}
 	defer insertStmt.Close()
+	// The same kanji can be in more than one entry, so ignore any tags it already has.
+	tagStmt, err := db.Prepare("INSERT OR IGNORE INTO wordtags (kanji, tag, kind) VALUES (:KJ, :TG, :KD)")
+	if err != nil {
+		return err
+	}
+	defer tagStmt.Close()
+	// Different entries can have the same word and reading, so ignore any that are already indexed.
+	readingStmt, err := db.Prepare("INSERT OR IGNORE INTO readings (reading, kanji) VALUES (:RD, :KJ)")
+	if err != nil {
+		return err
+	}
+	defer readingStmt.Close()
 	// Optimize the database insertion
 
 	if err := db.BeginTrans(); err != nil {
@@ -121,6 +188,14 @@ func insertWords(dict *jmdict, kptsmap kmap) error {
 				db.RollbackTrans()
 				return err
 			}
+			if err := saveTags(tagStmt, e); err != nil {
+				db.RollbackTrans()
+				return err
+			}
+			if err := saveReadings(readingStmt, e); err != nil {
+				db.RollbackTrans()
+				return err
+			}
 		}
 	}
 
@@ -157,8 +232,9 @@ func saveWord(insertStmt *sql.Stmt, e entry, kptsmap kmap) error {
 	if len(kanjis) > 0 {
 		// Get all of the entry kanji variants
 		hiragana := convertToHiragana(kana)
+		reading := convertToReading(kana)
 		for _, kanji := range kanjis {
-			if err := saveKanji(insertStmt, seq, kanji, hiragana, endsInN, kptsmap); err != nil {
+			if err := saveKanji(insertStmt, seq, kanji, hiragana, reading, endsInN, getKanjiFreq(e, kanji), kptsmap); err != nil {
 				return err
 			}
 		}
@@ -166,7 +242,7 @@ func saveWord(insertStmt *sql.Stmt, e entry, kptsmap kmap) error {
 		// Only kana for this entry.
 		for _, kn := range strings.Split(kana, ",") {
 			hiragana := convertToHiragana(kn)
-			if err := saveKanji(insertStmt, seq, kn, hiragana, endsInN, kptsmap); err != nil {
+			if err := saveKanji(insertStmt, seq, kn, hiragana, convertToReading(kn), endsInN, getKanaFreq(e, kn), kptsmap); err != nil {
 				return err
 			}
 		}
@@ -175,7 +251,7 @@ func saveWord(insertStmt *sql.Stmt, e entry, kptsmap kmap) error {
 	nokanjis := getNoKanjis(e)
 	if len(nokanjis) > 0 {
 		for _, nkn := range nokanjis {
-			if err := saveKanji(insertStmt, seq, nkn, convertToHiragana(nkn), endsInNExp.MatchString(nkn), kptsmap); err != nil {
+			if err := saveKanji(insertStmt, seq, nkn, convertToHiragana(nkn), convertToReading(nkn), endsInNExp.MatchString(nkn), getKanaFreq(e, nkn), kptsmap); err != nil {
 				return err
 			}
 		}
@@ -205,7 +281,106 @@ func convertToHiragana(kana string) string {
 	return hiragana
 }
 
-func saveKanji(insertStmt *sql.Stmt, seq string, kanji string, kana string, endsInN bool, kptsmap kmap) error {
+// Save the field (food, MA, Buddh, ...) and misc (arch, col, ...) tags of each word in the entry.
+func saveTags(tagStmt *sql.Stmt, e entry) error {
+	words := getKanjis(e)
+	if len(words) == 0 {
+		kana, _ := getKana(e)
+		words = strings.Split(kana, ",")
+	}
+	words = append(words, getNoKanjis(e)...)
+	for _, s := range e.Sense {
+		for _, word := range words {
+			if !senseAppliesTo(s, word) {
+				continue
+			}
+			for _, tag := range s.Field {
+				if _, err := tagStmt.Exec(sql.Named("KJ", word), sql.Named("TG", tag), sql.Named("KD", "field")); err != nil {
+					return err
+				}
+			}
+			for _, tag := range s.Misc {
+				if _, err := tagStmt.Exec(sql.Named("KJ", word), sql.Named("TG", tag), sql.Named("KD", "misc")); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Index each word of the entry by its hiragana readings, so the bot can find the words that a kana-only submission could be (i.e., はし → 橋, 箸, 端).
+func saveReadings(readingStmt *sql.Stmt, e entry) error {
+	for _, kanji := range getKanjis(e) {
+		for _, r := range e.Rele {
+			if r.NoKanji != nil || !readingAppliesTo(r, kanji) {
+				continue
+			}
+			if _, err := readingStmt.Exec(sql.Named("RD", convertToHiragana(r.Reb)), sql.Named("KJ", kanji)); err != nil {
+				return err
+			}
+		}
+	}
+	// Kana words are read as themselves, so katakana words can be typed in hiragana too.
+	words := getNoKanjis(e)
+	if len(getKanjis(e)) == 0 {
+		kana, _ := getKana(e)
+		words = strings.Split(kana, ",")
+	}
+	for _, word := range words {
+		if _, err := readingStmt.Exec(sql.Named("RD", convertToHiragana(word)), sql.Named("KJ", word)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// A reading may be restricted to only some of the kanji of its entry.
+func readingAppliesTo(r rele, kanji string) bool {
+	if len(r.RestrTo) == 0 {
+		return true
+	}
+	for _, k := range r.RestrTo {
+		if k == kanji {
+			return true
+		}
+	}
+	return false
+}
+
+// A sense may be restricted to only some of the kanji or readings of its entry.
+func senseAppliesTo(s sense, word string) bool {
+	restrictions := s.RestrToReading
+	if kanjiExp.MatchString(word) {
+		restrictions = s.RestrToKanji
+	}
+	if len(restrictions) == 0 {
+		return true
+	}
+	for _, r := range restrictions {
+		if r == word {
+			return true
+		}
+	}
+	return false
+}
+
+// Convert the katakana to hiragana, but keep the 'ー' so the bot can apply its long vowel rule.
+func convertToReading(kana string) string {
+	reading := ""
+	for _, kn := range kana {
+		thisKana := string(kn)
+		if hiragana, ok := kanaMap[thisKana]; ok {
+			reading += hiragana
+		} else {
+			// No conversion necessary.
+			reading += thisKana
+		}
+	}
+	return reading
+}
+
+func saveKanji(insertStmt *sql.Stmt, seq string, kanji string, kana string, reading string, endsInN bool, freq int, kptsmap kmap) error {
 	var pts int
 	if endsInN {
 		// Automatic zero for ending in 'ん'
@@ -213,9 +388,9 @@ func saveKanji(insertStmt *sql.Stmt, seq string, kanji string, kana string, ends
 	} else {
 		pts = getKanjiWordPts(kanji, kptsmap)
 	}
-	_, err := insertStmt.Exec(sql.Named("SQ", seq), sql.Named("KJ", &kanji), sql.Named("KN", &kana), sql.Named("SC", &pts))
+	_, err := insertStmt.Exec(sql.Named("SQ", seq), sql.Named("KJ", &kanji), sql.Named("KN", &kana), sql.Named("RD", &reading), sql.Named("SC", &pts), sql.Named("FQ", &freq))
 	if err != nil {
-		err = mergeRecords(seq, kanji, kana, pts)
+		err = mergeRecords(seq, kanji, kana, reading, pts, freq)
 		failcount++
 	} else {
 		insertcount++
@@ -223,20 +398,22 @@ func saveKanji(insertStmt *sql.Stmt, seq string, kanji string, kana string, ends
 	return err
 }
 
-func mergeRecords(seq string, kanji string, kana string, pts int) error {
-	var existingKana, existingSeq string
-	var existingPts int
-	err := db.SingleQuery(fmt.Sprintf("SELECT seq, kana, points FROM words WHERE kanji = '%s'", kanji),
-		&existingSeq, &existingKana, &existingPts)
+func mergeRecords(seq string, kanji string, kana string, reading string, pts int, freq int) error {
+	var existingKana, existingReading, existingSeq string
+	var existingPts, existingFreq int
+	err := db.SingleQuery(fmt.Sprintf("SELECT seq, kana, reading, points, freq FROM words WHERE kanji = '%s'", kanji),
+		&existingSeq, &existingKana, &existingReading, &existingPts, &existingFreq)
 	if err != nil {
 		return err
 	}
 
-	// Merge kana and the word pts.
+	// Merge kana, readings and the word pts.
 	newSeq := mergeStrings(existingSeq, seq)
 	newKana := mergeStrings(existingKana, kana)
+	newReading := mergeStrings(existingReading, reading)
 	newPts := mergePts(existingPts, pts)
-	return db.Exec("UPDATE words SET seq = ?, kana = ?, points = ? WHERE kanji = ?", &newSeq, &newKana, &newPts, &kanji)
+	newFreq := mergeFreq(existingFreq, freq)
+	return db.Exec("UPDATE words SET seq = ?, kana = ?, reading = ?, points = ?, freq = ? WHERE kanji = ?", &newSeq, &newKana, &newReading, &newPts, &newFreq, &kanji)
 }
 
 func mergeStrings(first string, second string) string {
@@ -266,6 +443,57 @@ func mergePts(first int, second int) int {
 	return second
 }
 
+// Keep the rank of the more common word. Zero means the word has no rank.
+func mergeFreq(first int, second int) int {
+	if first == 0 || (second != 0 && second < first) {
+		return second
+	}
+	return first
+}
+
+/*
+The ke_pri and re_pri priority tags mark the words that are in the
+frequency lists that JMdict was built from. The nfxx tags rank the
+words of the Mainichi Shimbun in sets of 500, from nf01 (most common)
+to nf48. The news, ichi, spec and gai tags split their lists in two,
+and the first half (news1, ...) is about the same as nf01 to nf24.
+The rank is 1 (most common) to 48, or 0 for a word in none of the lists.
+*/
+func getFreqRank(pri []string) int {
+	rank := 0
+	for _, p := range pri {
+		var r int
+		switch {
+		case strings.HasPrefix(p, "nf"):
+			r, _ = strconv.Atoi(strings.TrimPrefix(p, "nf"))
+		case strings.HasSuffix(p, "1"):
+			r = freqRankFirstHalf
+		case strings.HasSuffix(p, "2"):
+			r = freqRankSecondHalf
+		}
+		rank = mergeFreq(rank, r)
+	}
+	return rank
+}
+
+func getKanjiFreq(e entry, kanji string) int {
+	for _, k := range e.Kele {
+		if k.Keb == kanji {
+			return getFreqRank(k.Kpri)
+		}
+	}
+	return 0
+}
+
+func getKanaFreq(e entry, kana string) int {
+	for _, r := range e.Rele {
+		if r.Reb == kana {
+			return getFreqRank(r.Rpri)
+		}
+	}
+	return 0
+}
+
 func getKanjiWordPts(kanji string, kptsmap kmap) int {
 	// Words entirely of hiragana or katakana are worth 1 point.
 	pts := 1
@@ -354,23 +582,26 @@ func getKanjiDict() (*jmdict, error) {
 	return &dict, nil
 }
 
-func getKanjiPtsMap() (kmap, error) {
+// Get the points of each kanji, and its JLPT level and school grade.
+func getKanjiPtsMap() (kmap, lmap, error) {
 	data, err := loadXMLFile(kanjidictFileName)
 	if err != nil {
 		log.Printf("error: %v", err)
-		return nil, err
+		return nil, nil, err
 	}
 	kanji := kanjidic{}
 	err = xml.Unmarshal(data, &kanji)
 	if err != nil {
 		log.Printf("error: %v", err)
-		return nil, err
+		return nil, nil, err
 	}
 	kptsmap := make(kmap)
+	klevelmap := make(lmap)
 	for _, ch := range kanji.Character {
 		kptsmap[ch.Literal] = getCharacterPts(ch)
+		klevelmap[ch.Literal] = ch.Misc
 	}
-	return kptsmap, nil
+	return kptsmap, klevelmap, nil
 }
 
 func getCharacterPts(ch character) int {
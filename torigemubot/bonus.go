@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+)
+
+// Every this many words in the chain multiplies the points of a word once more (i.e., the 10th to 19th words are worth double).
+const chainBonusLength = 10
+
+// A player's streak of consecutive words adds a point for each word after the first, up to this many points.
+const maxStreakBonus = 5
+
+// The speed bonus for answering within each number of seconds of the last word.
+var speedBonuses = []struct {
+	seconds int64
+	pts     int
+}{
+	{seconds: 10, pts: 3},
+	{seconds: 30, pts: 2},
+	{seconds: 60, pts: 1},
+}
+
+// Add the bonus points of a word that is about to be added to the chain after the last word.
+func addWordBonuses(entry *wordEntry, lastentry *wordEntry) {
+	chainLength := len(getWordHistory(entry.chatid)) + 1
+	entry.chainBonus = entry.points * (chainLength / chainBonusLength)
+	entry.streakBonus = getPlayerStreak(entry.chatid, entry.userid) - 1
+	if entry.streakBonus > maxStreakBonus {
+		entry.streakBonus = maxStreakBonus
+	} else if entry.streakBonus < 0 {
+		entry.streakBonus = 0
+	}
+	if lastentry == nil || lastentry.sentAt == 0 {
+		// The date of the last word's message is not known.
+		return
+	}
+	// The time between the messages of the last word and the new word.
+	elapsed := entry.sentAt - lastentry.sentAt
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	for _, speed := range speedBonuses {
+		if elapsed <= speed.seconds {
+			entry.speedBonus = speed.pts
+			break
+		}
+	}
+}
+
+func (entry *wordEntry) getBonusPts() int {
+	return entry.chainBonus + entry.streakBonus + entry.speedBonus
+}
+
+func (entry *wordEntry) getBonusDisplay() string {
+	display := ""
+	if entry.chainBonus > 0 {
+		display += fmt.Sprintf("+連鎖%d", entry.chainBonus)
+	}
+	if entry.streakBonus > 0 {
+		display += fmt.Sprintf("+連続%d", entry.streakBonus)
+	}
+	if entry.speedBonus > 0 {
+		display += fmt.Sprintf("+速さ%d", entry.speedBonus)
+	}
+	return display
+}
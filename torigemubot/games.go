@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	tg "github.com/semog/go-bot-api/v5"
+)
+
+const maxGamesShown = 10
+
+// Show the latest games that were played in the chat.
+func doShowGames(bot *tg.BotAPI, msg *tg.Message) {
+	log.Println("Received games command.")
+	chatID := msg.Chat.ID
+	games := getGames(chatID, maxGamesShown)
+	if len(games) == 0 {
+		sendReplyMsg(bot, msg, "終わったゲームはまだありません。")
+		return
+	}
+	gameList := "<b>過去のゲーム</b>\n＿＿＿＿＿＿＿＿＿＿＿"
+	for _, game := range games {
+		gameList += "\n" + html.EscapeString(getGameDisplay(game))
+	}
+	gameList += "\n\nゲームの言葉は /game 番号 で見られます。"
+	reply := tg.NewMessage(chatID, gameList)
+	reply.ParseMode = tg.ModeHTML
+	bot.Send(reply)
+}
+
+// Show the words of a past game (i.e., /game 12).
+func doShowGame(bot *tg.BotAPI, msg *tg.Message) {
+	log.Println("Received game command.")
+	chatID := msg.Chat.ID
+	args := strings.TrimPrefix(strings.TrimSpace(msg.CommandArguments()), "#")
+	if len(args) == 0 {
+		doShowGames(bot, msg)
+		return
+	}
+	gameID, err := strconv.ParseInt(args, 10, 64)
+	var game *gameEntry
+	if err == nil {
+		game = getGame(chatID, gameID)
+	}
+	if game == nil {
+		sendReplyMsg(bot, msg, fmt.Sprintf("❌ゲームがありません: %s\nゲームの一覧は /games です。", args))
+		return
+	}
+	// Player names and loss reasons may have characters that are markup.
+	gameWords := html.EscapeString(getGameDisplay(game)) + "\n＿＿＿＿＿＿＿＿＿＿＿"
+	for _, entry := range getGameWords(chatID, game.gameid) {
+		gameWords += "\n" + html.EscapeString(getWordEntryDisplay(chatID, entry, true))
+	}
+	reply := tg.NewMessage(chatID, gameWords)
+	reply.ParseMode = tg.ModeHTML
+	bot.Send(reply)
+}
+
+func getGameDisplay(game *gameEntry) string {
+	display := fmt.Sprintf("#%d %s", game.gameid, time.Unix(game.started, 0).Format("01/02 15:04"))
+	if game.ended == 0 {
+		return display + " 【ゲーム中】"
+	}
+	display += fmt.Sprintf("〜%s「%d言葉」", time.Unix(game.ended, 0).Format("15:04"), game.chain)
+	if player, err := getPlayerByID(game.chatid, game.loserid); game.loserid != 0 && err == nil {
+		display += fmt.Sprintf(" ❌%s", formatPlayerName(player))
+	}
+	if len(game.reason) > 0 {
+		display += fmt.Sprintf("\n　%s", game.reason)
+	}
+	return display
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+const gamesTableName = "games"
+
+// A game of a chat. The game is being played until it has ended.
+type gameEntry struct {
+	gameid  int64
+	chatid  int64
+	started int64
+	ended   int64
+	loserid int64
+	reason  string
+	chain   int
+}
+
+func startGame(chatID int64) (int64, error) {
+	if err := gamedb.Exec(fmt.Sprintf("INSERT INTO %s (chatid, started, ended, loserid, reason, chain) VALUES (?, ?, 0, 0, '', 0)", gamesTableName),
+		chatID, time.Now().Unix()); err != nil {
+		return 0, err
+	}
+	gameID := getGameID(chatID)
+	if gameID == 0 {
+		return 0, fmt.Errorf("could not start a game in chat [%d]", chatID)
+	}
+	return gameID, nil
+}
+
+// The ID of the game that is being played, or zero when no word has been played yet.
+func getGameID(chatID int64) int64 {
+	var gameID int64
+	gamedb.SingleQuery(fmt.Sprintf("SELECT gameid FROM %s WHERE chatid = %d AND ended = 0 ORDER BY gameid DESC LIMIT 1", gamesTableName, chatID), &gameID)
+	return gameID
+}
+
+// End the game that is being played, keeping its words for the archive.
+// Only the first ending is recorded, so the player who lost it is not overwritten.
+func endGame(chatID int64, loserID int64, reason string) error {
+	return gamedb.Exec(fmt.Sprintf("UPDATE %s SET ended = ?, loserid = ?, reason = ?, chain = (SELECT COUNT(*) FROM %s WHERE %s.gameid = %s.gameid) WHERE chatid = ? AND ended = 0",
+		gamesTableName, usedwordsTableName, usedwordsTableName, gamesTableName), time.Now().Unix(), loserID, reason, chatID)
+}
+
+func getGame(chatID int64, gameID int64) *gameEntry {
+	game := &gameEntry{
+		chatid: chatID,
+	}
+	if nil != gamedb.SingleQuery(fmt.Sprintf("SELECT gameid, started, ended, loserid, reason, chain FROM %s WHERE chatid = %d AND gameid = %d", gamesTableName, chatID, gameID),
+		&game.gameid, &game.started, &game.ended, &game.loserid, &game.reason, &game.chain) {
+		return nil
+	}
+	return game
+}
+
+// Get the latest games of the chat that have ended, with the newest first.
+func getGames(chatID int64, limit int) []*gameEntry {
+	games := make([]*gameEntry, 0)
+	gamedb.MultiQuery(fmt.Sprintf("SELECT gameid, started, ended, loserid, reason, chain FROM %s WHERE chatid = %d AND ended != 0 ORDER BY gameid DESC LIMIT %d", gamesTableName, chatID, limit),
+		func(rows *sql.Rows) error {
+			game := &gameEntry{
+				chatid: chatID,
+			}
+			rows.Scan(&game.gameid, &game.started, &game.ended, &game.loserid, &game.reason, &game.chain)
+			games = append(games, game)
+			return nil
+		})
+	return games
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	tg "github.com/semog/go-bot-api/v5"
+	"k8s.io/klog"
+)
+
+// A JMdict field tag that limits the game to a genre of words.
+type genreEntry struct {
+	tag      string
+	numWords int
+}
+
+// Show the genres, or limit the game to one with "/genre tag". Use "/genre off" to play with all words again.
+func doGenre(bot *tg.BotAPI, msg *tg.Message) {
+	log.Println("Received genre command.")
+	chatID := msg.Chat.ID
+	args := strings.TrimSpace(msg.CommandArguments())
+	genres := getGenres()
+	if len(args) == 0 {
+		doShowGenres(bot, chatID, genres)
+		return
+	}
+	if !isChatAdmin(bot, msg.Chat, msg.From) {
+		sendReplyMsg(bot, msg, notAdminMsg)
+		return
+	}
+	if strings.ToLower(args) == settingOff {
+		if err := setSetting(chatID, settingGenre, settingOff); err != nil {
+			klog.Error(err)
+			sendReplyMsg(bot, msg, "❌誤りです。ジャンルを変更できませんでした。")
+			return
+		}
+		bot.Send(tg.NewMessage(chatID, "ジャンルの制限はなくなりました。"))
+		newGame(bot, msg.Chat.ID)
+		return
+	}
+	var genre *genreEntry
+	for _, g := range genres {
+		if strings.EqualFold(g.tag, args) {
+			genre = g
+		}
+	}
+	if genre == nil {
+		sendReplyMsg(bot, msg, fmt.Sprintf("❌ジャンルがありません: %s\nジャンルの一覧は /genre です。", args))
+		return
+	}
+	if err := setSetting(chatID, settingGenre, genre.tag); err != nil {
+		klog.Error(err)
+		sendReplyMsg(bot, msg, "❌誤りです。ジャンルを変更できませんでした。")
+		return
+	}
+	bot.Send(tg.NewMessage(chatID, fmt.Sprintf("ジャンルは「%s」になりました。(%d言葉)", genre.tag, genre.numWords)))
+	newGame(bot, msg.Chat.ID)
+}
+
+func doShowGenres(bot *tg.BotAPI, chatID int64, genres []*genreEntry) {
+	display := fmt.Sprintf("*ジャンル*\n現在のジャンル: %s\n＿＿＿＿＿＿＿＿＿＿＿", getSetting(chatID, settingGenre))
+	for _, genre := range genres {
+		display += fmt.Sprintf("\n%s 「%d言葉」", genre.tag, genre.numWords)
+	}
+	if len(genres) == 0 {
+		display += "\nジャンルがありません。"
+	}
+	msg := tg.NewMessage(chatID, display)
+	msg.ParseMode = tg.ModeMarkdown
+	bot.Send(msg)
+}
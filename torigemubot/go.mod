@@ -5,6 +5,7 @@ go 1.23
 require (
 	github.com/semog/go-bot-api/v5 v5.5.1
 	github.com/semog/go-sqldb v1.0.4
+	golang.org/x/text v0.21.0
 	k8s.io/klog v0.4.0
 )
 
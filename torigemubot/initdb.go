@@ -41,4 +41,109 @@ var gamedbPatchFuncs = []sqldb.PatchFuncType{
 		}
 		return sdb.CreateIndex("customwords_idx ON customwords (chatid, kanji)")
 	}},
+	{PatchID: 3, PatchFunc: func(sdb *sqldb.SQLDb) error {
+		if err := sdb.CreateTable("settings (chatid INTEGER, name TEXT, value TEXT)"); err != nil {
+			return err
+		}
+		return sdb.CreateIndex("settings_idx ON settings (chatid, name)")
+	}},
+	{PatchID: 4, PatchFunc: func(sdb *sqldb.SQLDb) error {
+		return sdb.Exec("ALTER TABLE usedwords ADD COLUMN reading TEXT DEFAULT ''")
+	}},
+	{PatchID: 5, PatchFunc: func(sdb *sqldb.SQLDb) error {
+		return sdb.CreateTable("turntimers (chatid INTEGER PRIMARY KEY, userid INTEGER, deadline INTEGER, warnings INTEGER)")
+	}},
+	{PatchID: 6, PatchFunc: func(sdb *sqldb.SQLDb) error {
+		if err := sdb.CreateTable("lobbies (chatid INTEGER PRIMARY KEY, started INTEGER, turnpos INTEGER)"); err != nil {
+			return err
+		}
+		return sdb.CreateTable("lobbyplayers (chatid INTEGER, userid INTEGER, position INTEGER, missed INTEGER, PRIMARY KEY (chatid, userid))")
+	}},
+	{PatchID: 7, PatchFunc: func(sdb *sqldb.SQLDb) error {
+		if err := sdb.Exec("ALTER TABLE players ADD COLUMN wins INTEGER DEFAULT 0"); err != nil {
+			return err
+		}
+		return sdb.CreateTable("lives (chatid INTEGER, userid INTEGER, lives INTEGER, PRIMARY KEY (chatid, userid))")
+	}},
+	{PatchID: 8, PatchFunc: func(sdb *sqldb.SQLDb) error {
+		return sdb.Exec("ALTER TABLE usedwords ADD COLUMN matched TEXT DEFAULT ''")
+	}},
+	{PatchID: 9, PatchFunc: func(sdb *sqldb.SQLDb) error {
+		return sdb.Exec("ALTER TABLE usedwords ADD COLUMN chosen TEXT DEFAULT ''")
+	}},
+	{PatchID: 10, PatchFunc: func(sdb *sqldb.SQLDb) error {
+		return sdb.CreateTable("currentwordmsgs (chatid INTEGER PRIMARY KEY, messageid INTEGER, word TEXT)")
+	}},
+	{PatchID: 11, PatchFunc: func(sdb *sqldb.SQLDb) error {
+		return sdb.CreateTable("statusmsgs (chatid INTEGER PRIMARY KEY, messageid INTEGER)")
+	}},
+	{PatchID: 12, PatchFunc: func(sdb *sqldb.SQLDb) error {
+		for _, column := range []string{"chainbonus", "streakbonus", "speedbonus"} {
+			if err := sdb.Exec("ALTER TABLE usedwords ADD COLUMN " + column + " INTEGER DEFAULT 0"); err != nil {
+				return err
+			}
+		}
+		return sdb.Exec("ALTER TABLE players ADD COLUMN streak INTEGER DEFAULT 0")
+	}},
+	{PatchID: 13, PatchFunc: func(sdb *sqldb.SQLDb) error {
+		if err := sdb.CreateTable("scoreevents (eventid INTEGER PRIMARY KEY AUTOINCREMENT, chatid INTEGER, userid INTEGER, delta INTEGER, reason TEXT, word TEXT, gameid INTEGER, time INTEGER)"); err != nil {
+			return err
+		}
+		if err := sdb.CreateIndex("scoreevents_idx ON scoreevents (chatid, userid)"); err != nil {
+			return err
+		}
+		// The scores from before the ledger are its opening balances.
+		return sdb.Exec("INSERT INTO scoreevents (chatid, userid, delta, reason, word, gameid, time) SELECT chatid, userid, score, 'balance', '', 0, strftime('%s', 'now') FROM players WHERE score != 0")
+	}},
+	{PatchID: 14, PatchFunc: func(sdb *sqldb.SQLDb) error {
+		if err := sdb.CreateTable("games (gameid INTEGER PRIMARY KEY AUTOINCREMENT, chatid INTEGER, started INTEGER, ended INTEGER, loserid INTEGER, reason TEXT, chain INTEGER)"); err != nil {
+			return err
+		}
+		if err := sdb.CreateIndex("gameschat_idx ON games (chatid, ended)"); err != nil {
+			return err
+		}
+		// The used words are the moves of the game that they were played in.
+		if err := sdb.Exec("ALTER TABLE usedwords ADD COLUMN gameid INTEGER DEFAULT 0"); err != nil {
+			return err
+		}
+		if err := sdb.CreateIndex("usedwordsgame_idx ON usedwords (gameid)"); err != nil {
+			return err
+		}
+		// The chains that are being played become the first games.
+		if err := sdb.Exec("INSERT INTO games (chatid, started, ended, loserid, reason, chain) SELECT chatid, MIN(wordindex), 0, 0, '', 0 FROM usedwords GROUP BY chatid"); err != nil {
+			return err
+		}
+		if err := sdb.Exec("UPDATE usedwords SET gameid = (SELECT gameid FROM games WHERE games.chatid = usedwords.chatid)"); err != nil {
+			return err
+		}
+		// The score events identified their game by the index of its first word.
+		return sdb.Exec("UPDATE scoreevents SET gameid = COALESCE((SELECT gameid FROM games WHERE games.chatid = scoreevents.chatid AND games.started = scoreevents.gameid), 0)")
+	}},
+	{PatchID: 15, PatchFunc: func(sdb *sqldb.SQLDb) error {
+		return sdb.Exec("ALTER TABLE lobbies ADD COLUMN turnstarted INTEGER DEFAULT 0")
+	}},
+	{PatchID: 16, PatchFunc: func(sdb *sqldb.SQLDb) error {
+		// The points awarded for adding a custom word are refunded exactly when it is removed.
+		if err := sdb.Exec("ALTER TABLE customwords ADD COLUMN addpts INTEGER DEFAULT 1"); err != nil {
+			return err
+		}
+		// Use the award recorded in the score events ledger, or else the chat's setting.
+		return sdb.Exec(`UPDATE customwords SET addpts = COALESCE(
+			(SELECT delta FROM scoreevents WHERE scoreevents.chatid = customwords.chatid AND scoreevents.userid = customwords.userid AND scoreevents.word = customwords.kanji AND reason = 'addword' ORDER BY eventid DESC LIMIT 1),
+			(SELECT CAST(value AS INTEGER) FROM settings WHERE settings.chatid = customwords.chatid AND name = 'addpts'),
+			1)`)
+	}},
+	{PatchID: 17, PatchFunc: func(sdb *sqldb.SQLDb) error {
+		// The scoring policy that each word was scored with, and how it scored the word.
+		for _, column := range []string{"scoring", "breakdown"} {
+			if err := sdb.Exec("ALTER TABLE usedwords ADD COLUMN " + column + " TEXT DEFAULT ''"); err != nil {
+				return err
+			}
+		}
+		return nil
+	}},
+	{PatchID: 18, PatchFunc: func(sdb *sqldb.SQLDb) error {
+		// The date of the message that played the word, which the speed bonus is measured from.
+		return sdb.Exec("ALTER TABLE usedwords ADD COLUMN sentat INTEGER DEFAULT 0")
+	}},
 }
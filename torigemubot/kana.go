@@ -0,0 +1,154 @@
+package main
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Voiced and semi-voiced kana, mapped to their plain kana.
+var dakutenMap = map[string]string{
+	"が": "か",
+	"ぎ": "き",
+	"ぐ": "く",
+	"げ": "け",
+	"ご": "こ",
+	"ざ": "さ",
+	"じ": "し",
+	"ず": "す",
+	"ぜ": "せ",
+	"ぞ": "そ",
+	"だ": "た",
+	"ぢ": "ち",
+	"づ": "つ",
+	"で": "て",
+	"ど": "と",
+	"ば": "は",
+	"び": "ひ",
+	"ぶ": "ふ",
+	"べ": "へ",
+	"ぼ": "ほ",
+	"ぱ": "は",
+	"ぴ": "ひ",
+	"ぷ": "ふ",
+	"ぺ": "へ",
+	"ぽ": "ほ",
+	"ゔ": "う",
+	"ガ": "カ",
+	"ギ": "キ",
+	"グ": "ク",
+	"ゲ": "ケ",
+	"ゴ": "コ",
+	"ザ": "サ",
+	"ジ": "シ",
+	"ズ": "ス",
+	"ゼ": "セ",
+	"ゾ": "ソ",
+	"ダ": "タ",
+	"ヂ": "チ",
+	"ヅ": "ツ",
+	"デ": "テ",
+	"ド": "ト",
+	"バ": "ハ",
+	"ビ": "ヒ",
+	"ブ": "フ",
+	"ベ": "ヘ",
+	"ボ": "ホ",
+	"パ": "ハ",
+	"ピ": "ヒ",
+	"プ": "フ",
+	"ペ": "ヘ",
+	"ポ": "ホ",
+	"ヴ": "ウ",
+}
+
+// Remove the dakuten or handakuten from a kana (i.e., ぷ → ふ).
+func plainKana(kana string) string {
+	if plain, ok := dakutenMap[kana]; ok {
+		return plain
+	}
+	return kana
+}
+
+// Get the plain kana and all of its voiced and semi-voiced variants (i.e., ふ → ふ, ぶ, ぷ).
+func dakutenVariants(kana string) []string {
+	plain := plainKana(kana)
+	variants := []string{plain}
+	for voiced, p := range dakutenMap {
+		if p == plain {
+			variants = append(variants, voiced)
+		}
+	}
+	// Keep the variants in kana order, since map order is random.
+	sort.Strings(variants[1:])
+	return variants
+}
+
+// Small kana that combine with the kana before them into a single mora.
+const smallKana = "ぁぃぅぇぉゃゅょゎァィゥェォャュョヮ"
+
+// The vowel that each hiragana ends with.
+var kanaVowelRows = map[string]string{
+	"あ": "あかがさざただなはばぱまやらわぁゃゎ",
+	"い": "いきぎしじちぢにひびぴみりゐぃ",
+	"う": "うくぐすずつづぬふぶぷむゆるゔぅゅ",
+	"え": "えけげせぜてでねへべぺめれゑぇ",
+	"お": "おこごそぞとどのほぼぽもよろをぉょ",
+}
+
+// Split the kana into morae. Small kana belong to the mora before them (i.e., しゃ), while っ, ー and ん are morae of their own.
+func splitMorae(kana string) []string {
+	morae := make([]string, 0)
+	for _, k := range kana {
+		if len(morae) > 0 && strings.ContainsRune(smallKana, k) {
+			morae[len(morae)-1] += string(k)
+		} else {
+			morae = append(morae, string(k))
+		}
+	}
+	return morae
+}
+
+// Replace each 'ー' with the vowel that it extends (i.e., こーひー → こおひい).
+func expandLongVowels(reading string) string {
+	expanded := make([]rune, 0)
+	for _, k := range reading {
+		if k == 'ー' && len(expanded) > 0 {
+			for vowel, row := range kanaVowelRows {
+				if strings.ContainsRune(row, expanded[len(expanded)-1]) {
+					k = []rune(vowel)[0]
+					break
+				}
+			}
+		}
+		expanded = append(expanded, k)
+	}
+	return string(expanded)
+}
+
+// Convert the katakana to hiragana, but keep the 'ー' (i.e., コーヒー → こーひー).
+func toHiragana(kana string) string {
+	hiragana := make([]rune, 0)
+	for _, k := range kana {
+		if k >= 'ァ' && k <= 'ヶ' {
+			k -= 'ァ' - 'ぁ'
+		}
+		hiragana = append(hiragana, k)
+	}
+	return string(hiragana)
+}
+
+// Check whether the word is written in hiragana and katakana only.
+func isKanaWord(word string) bool {
+	for _, k := range word {
+		if k != 'ー' && !unicode.In(k, unicode.Hiragana, unicode.Katakana) {
+			return false
+		}
+	}
+	return len(word) > 0
+}
+
+// Count the morae of a reading. Small kana don't count, but っ and ー do (i.e., ちょっと has 3 morae).
+func countMorae(reading string) int {
+	return len(splitMorae(reading))
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitMorae(t *testing.T) {
+	tests := []struct {
+		kana  string
+		morae []string
+	}{
+		{"すし", []string{"す", "し"}},
+		{"じてんしゃ", []string{"じ", "て", "ん", "しゃ"}},
+		{"ちょっと", []string{"ちょ", "っ", "と"}},
+		{"らーめん", []string{"ら", "ー", "め", "ん"}},
+		{"ファイル", []string{"ファ", "イ", "ル"}},
+		{"", []string{}},
+	}
+	for _, test := range tests {
+		if morae := splitMorae(test.kana); !reflect.DeepEqual(morae, test.morae) {
+			t.Errorf("splitMorae(%q) = %q; want %q", test.kana, morae, test.morae)
+		}
+	}
+}
+
+func TestCountMorae(t *testing.T) {
+	tests := []struct {
+		reading string
+		morae   int
+	}{
+		{"すし", 2},
+		{"きゃく", 2},
+		{"ちょっと", 3},
+		{"こーひー", 4},
+		{"がっこう", 4},
+		{"しんぶん", 4},
+		{"", 0},
+	}
+	for _, test := range tests {
+		if morae := countMorae(test.reading); morae != test.morae {
+			t.Errorf("countMorae(%q) = %d; want %d", test.reading, morae, test.morae)
+		}
+	}
+}
+
+func TestExpandLongVowels(t *testing.T) {
+	tests := []struct {
+		reading  string
+		expanded string
+	}{
+		{"こーひー", "こおひい"},
+		{"みきさー", "みきさあ"},
+		{"るびー", "るびい"},
+		{"しゃつー", "しゃつう"},
+		{"すし", "すし"},
+	}
+	for _, test := range tests {
+		if expanded := expandLongVowels(test.reading); expanded != test.expanded {
+			t.Errorf("expandLongVowels(%q) = %q; want %q", test.reading, expanded, test.expanded)
+		}
+	}
+}
+
+func TestToHiragana(t *testing.T) {
+	tests := []struct {
+		kana     string
+		hiragana string
+	}{
+		{"コーヒー", "こーひー"},
+		{"ヴァイオリン", "ゔぁいおりん"},
+		{"すし", "すし"},
+	}
+	for _, test := range tests {
+		if hiragana := toHiragana(test.kana); hiragana != test.hiragana {
+			t.Errorf("toHiragana(%q) = %q; want %q", test.kana, hiragana, test.hiragana)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Get the points of a word for the chat's game mode, the kana reading(s) it was played with,
+// and the breakdown of how the chat's scoring policy scored it.
+// The chosen reading, if any, is the only reading that the word is played with.
+// If the word is not allowed, then the points are zero and the message explains why.
+func getModeWordPts(chatID int64, theWord string, chosen string, lastEntry *wordEntry) (int, string, string, string) {
+	var pts int
+	var reading, breakdown, msg string
+	switch getSetting(chatID, settingMode) {
+	case modeKanji:
+		pts, reading, msg = getKanjiModeWordPts(chatID, theWord, lastEntry)
+	default:
+		pts, reading, msg = getWordPts(chatID, theWord, chosen, lastEntry)
+	}
+	if pts != 0 {
+		// The chat's scoring policy has the final say on the points of a valid word.
+		pts, breakdown = scoreWord(chatID, theWord, reading, pts)
+	}
+	return pts, reading, breakdown, msg
+}
+
+// In kanji shiritori (漢字しりとり), the next word must begin with the last kanji of the last word (i.e., 学校 → 校長 → 長所).
+// Words may end in 'ん', but every word must end in a kanji.
+func getKanjiModeWordPts(chatID int64, theWord string, lastEntry *wordEntry) (int, string, string) {
+	found, kana, _ := lookupStandardKana(theWord)
+	if !found {
+		if found, kana, _ = lookupCustomKana(chatID, theWord); !found {
+			return 0, "", fmt.Sprintf("無効言葉: %s", theWord)
+		}
+	}
+	lastKanji := getLastKanji(theWord)
+	if len(lastKanji) == 0 {
+		return 0, "", fmt.Sprintf("言葉は漢字で終わらなければなりません: %s", theWord)
+	}
+	if genre := getSetting(chatID, settingGenre); genre != settingOff && !wordHasTag(theWord, genre) {
+		return 0, "", fmt.Sprintf("ジャンル「%s」の言葉ではありません: %s", genre, theWord)
+	}
+	if harder := getHarderKanji(chatID, theWord); len(harder) > 0 {
+		return 0, "", fmt.Sprintf("難しすぎる漢字があります: %s「%s」", theWord, strings.Join(harder, "・"))
+	}
+	if lastEntry != nil {
+		expected := getLastKanji(lastEntry.word)
+		if len(expected) == 0 {
+			// The chain was started in another mode, so there is no kanji to continue from.
+			return 0, "", fmt.Sprintf("前の言葉は漢字で終わらないので、続けられません: %s", lastEntry.word)
+		}
+		if !strings.HasPrefix(theWord, expected) {
+			return 0, "", fmt.Sprintf("初めの漢字は終わりの漢字と一致しません: %s -> %s\n次の言葉は「%s」で始まる必要があります。", lastEntry.word, theWord, expected)
+		}
+	}
+	return calcKanjiModePoints(theWord), kana, ""
+}
+
+// Get the last kanji of the word, or nothing if the word ends in kana.
+func getLastKanji(theWord string) string {
+	kanji := []rune(theWord)
+	last := len(kanji) - 1
+	if last > 0 && kanji[last] == '々' {
+		// The 々 iteration mark repeats the kanji before it (i.e., 人々 ends in 人).
+		last--
+	}
+	if last < 0 || !isKanji(kanji[last]) {
+		return ""
+	}
+	return string(kanji[last])
+}
+
+// Every kanji of the word adds its points, so longer compound words are worth more.
+func calcKanjiModePoints(theWord string) int {
+	pts := 0
+	for _, k := range theWord {
+		if isKanji(k) {
+			kpts := getKanjiPoints(string(k))
+			if kpts < 1 {
+				kpts = 1
+			}
+			pts += kpts
+		}
+	}
+	return pts
+}
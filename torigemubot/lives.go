@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tg "github.com/semog/go-bot-api/v5"
+)
+
+// A mistake ends the game, unless the chat plays with lives.
+func playerMadeMistake(bot *tg.BotAPI, player *playerEntry, reason string) {
+	// A mistake ends the player's streak of words.
+	updatePlayerStreak(player.chatid, player.userid, 0)
+	if getIntSetting(player.chatid, settingLives) == 0 {
+		userLostGame(bot, player, reason)
+		newGame(bot, player.chatid)
+		return
+	}
+	playerLostLife(bot, player, reason)
+}
+
+// Take a life from the player, but keep the chain going until only one player is left standing.
+func playerLostLife(bot *tg.BotAPI, player *playerEntry, reason string) {
+	chatID := player.chatid
+	lives := getLives(chatID, player.userid) - 1
+	if lives < 0 {
+		lives = 0
+	}
+	// The next player in the play order gets to answer the same word.
+	// The turn passes on before the life is taken, so the next player doesn't get skipped with a player that is knocked out.
+	advanceLobbyTurn(chatID)
+	setLives(chatID, player.userid, lives)
+	if lives > 0 {
+		bot.Send(tg.NewMessage(chatID, fmt.Sprintf("💔%s様はライフを失いました！\n%s\n残りのライフ: %s", formatPlayerName(player), reason, strings.Repeat("❤", lives))))
+	} else {
+		bot.Send(tg.NewMessage(chatID, fmt.Sprintf("☠%s様は脱落しました！\n%s\n＿|￣|○", formatPlayerName(player), reason)))
+	}
+	standing := make([]*livesEntry, 0)
+	entries := getAllLives(chatID)
+	for _, entry := range entries {
+		if entry.lives > 0 {
+			standing = append(standing, entry)
+		}
+	}
+	switch {
+	case len(standing) == 0:
+		endGame(chatID, player.userid, reason)
+		newGame(bot, chatID)
+	case len(standing) == 1 && len(entries) > 1:
+		playerWonGame(bot, chatID, standing[0].userid)
+		// The last player to be knocked out lost the game.
+		endGame(chatID, player.userid, reason)
+		newGame(bot, chatID)
+	default:
+		nextID := int64(0)
+		if turn := getLobbyTurn(chatID); turn != nil {
+			nextID = turn.userid
+		}
+		if getLastEntry(chatID) != nil {
+			startTurnTimer(chatID, nextID)
+		}
+		if updateStatusMsg(bot, chatID) {
+			bot.Send(tg.NewMessage(chatID, fmt.Sprintf("残りのライフ：%s", getLivesDisplay(chatID))))
+		} else {
+			bot.Send(tg.NewMessage(chatID, fmt.Sprintf("残りのライフ：%s\n\n現在の言葉は：\n%s", getLivesDisplay(chatID), getCurrentWordEntryDisplay(&tg.Chat{ID: chatID}, false))))
+		}
+		announceLobbyTurn(bot, chatID)
+	}
+}
+
+func playerWonGame(bot *tg.BotAPI, chatID int64, userID int64) {
+	bonus := getIntSetting(chatID, settingWinPts)
+	updatePlayerScore(chatID, userID, bonus, scoreReasonWin, "")
+	updatePlayerWins(chatID, userID, 1)
+	player, _ := getPlayerByID(chatID, userID)
+	bot.Send(tg.NewMessage(chatID, fmt.Sprintf("🏆%s様の勝ちです！おめでとうございます！\n勝者のボーナス【%d得点】\n＼(^o^)／", formatPlayerName(player), bonus)))
+}
+
+// Players that have no lives left have to wait for the next game.
+func playerIsOut(chatID int64, userID int64) bool {
+	return getIntSetting(chatID, settingLives) != 0 && getLives(chatID, userID) == 0
+}
+
+func getLivesDisplay(chatID int64) string {
+	display := ""
+	for _, entry := range getAllLives(chatID) {
+		player, _ := getPlayerByID(chatID, entry.userid)
+		if entry.lives > 0 {
+			display += fmt.Sprintf("\n%s %s", formatPlayerName(player), strings.Repeat("❤", entry.lives))
+		} else {
+			display += fmt.Sprintf("\n%s ☠", formatPlayerName(player))
+		}
+	}
+	return display
+}
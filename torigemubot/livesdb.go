@@ -0,0 +1,45 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+const livesTableName = "lives"
+
+// The lives that a player has left in the current game.
+type livesEntry struct {
+	userid int64
+	lives  int
+}
+
+// Get the lives that the player has left. Players get the full number of lives the first time they play in a game.
+func getLives(chatID int64, userID int64) int {
+	var lives int
+	if nil != gamedb.SingleQuery(fmt.Sprintf("SELECT lives FROM %s WHERE chatid = %d AND userid = %d", livesTableName, chatID, userID), &lives) {
+		lives = getIntSetting(chatID, settingLives)
+		setLives(chatID, userID, lives)
+	}
+	return lives
+}
+
+func setLives(chatID int64, userID int64, lives int) error {
+	return gamedb.Exec(fmt.Sprintf("INSERT OR REPLACE INTO %s (chatid, userid, lives) VALUES (?, ?, ?)", livesTableName), chatID, userID, lives)
+}
+
+// Get the players of the current game, with the most lives first.
+func getAllLives(chatID int64) []*livesEntry {
+	entries := make([]*livesEntry, 0)
+	gamedb.MultiQuery(fmt.Sprintf("SELECT userid, lives FROM %s WHERE chatid = %d ORDER BY lives DESC", livesTableName, chatID),
+		func(rows *sql.Rows) error {
+			entry := &livesEntry{}
+			rows.Scan(&entry.userid, &entry.lives)
+			entries = append(entries, entry)
+			return nil
+		})
+	return entries
+}
+
+func clearLives(chatID int64) error {
+	return gamedb.Exec(fmt.Sprintf("DELETE FROM %s WHERE chatid = %d", livesTableName, chatID))
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"log"
+	"time"
+
+	tg "github.com/semog/go-bot-api/v5"
+	"k8s.io/klog"
+)
+
+// Join the play order of the chat. Players that were skipped for missing their turns can rejoin the same way.
+func doJoin(bot *tg.BotAPI, msg *tg.Message) {
+	log.Println("Received join command.")
+	chatID := msg.Chat.ID
+	if msg.Chat.IsPrivate() {
+		sendReplyMsg(bot, msg, "順番はグループで決めます。")
+		return
+	}
+	player := getPlayer(chatID, msg.From)
+	lp := getLobbyPlayer(chatID, player.userid)
+	switch {
+	case lp == nil:
+		if err := addLobbyPlayer(chatID, player.userid); err != nil {
+			klog.Error(err)
+			sendReplyMsg(bot, msg, "❌誤りです。参加できませんでした。")
+			return
+		}
+		sendReplyMsg(bot, msg, fmt.Sprintf("%s様が参加しました。(^_^)/", formatPlayerName(player)))
+	case !lp.active():
+		updateMissedTurns(chatID, player.userid, 0)
+		sendReplyMsg(bot, msg, fmt.Sprintf("%s様、おかえりなさい。(^_^)/", formatPlayerName(player)))
+	default:
+		sendReplyMsg(bot, msg, fmt.Sprintf("%s様は既に参加しています。", formatPlayerName(player)))
+	}
+	doShowLobby(bot, chatID)
+}
+
+// Leave the play order of the chat.
+func doLeave(bot *tg.BotAPI, msg *tg.Message) {
+	log.Println("Received leave command.")
+	chatID := msg.Chat.ID
+	player := getPlayer(chatID, msg.From)
+	if getLobbyPlayer(chatID, player.userid) == nil {
+		sendReplyMsg(bot, msg, fmt.Sprintf("%s様は参加していません。", formatPlayerName(player)))
+		return
+	}
+	turn := getLobbyTurn(chatID)
+	if err := removeLobbyPlayer(chatID, player.userid); err != nil {
+		klog.Error(err)
+		sendReplyMsg(bot, msg, "❌誤りです。抜けられませんでした。")
+		return
+	}
+	sendReplyMsg(bot, msg, fmt.Sprintf("%s様が抜けました。m(_ _)m", formatPlayerName(player)))
+	if !checkLobbyPlayers(bot, chatID) {
+		return
+	}
+	if turn != nil && turn.userid == player.userid {
+		// The next player takes over the turn of the player that left.
+		if getLastEntry(chatID) != nil {
+			startTurnTimer(chatID, getNextPlayerID(msg.Chat))
+		}
+		announceLobbyTurn(bot, chatID)
+	}
+}
+
+// Start a new game where the players that joined take turns in a fixed order.
+func doStart(bot *tg.BotAPI, msg *tg.Message) {
+	log.Println("Received start command.")
+	chatID := msg.Chat.ID
+	if msg.Chat.IsPrivate() {
+		// Private chats send /start when they first open the bot.
+		doHelp(bot, msg)
+		return
+	}
+	if getLobbyTurn(chatID) != nil {
+		doShowLobby(bot, chatID)
+		announceLobbyTurn(bot, chatID)
+		return
+	}
+	var first *lobbyPlayer
+	numActive := 0
+	for _, lp := range getLobbyPlayers(chatID) {
+		if lp.active() {
+			if first == nil {
+				first = lp
+			}
+			numActive++
+		}
+	}
+	if numActive < 2 {
+		sendReplyMsg(bot, msg, "二人以上が /join で参加してから /start して下さい。")
+		return
+	}
+	if err := setLobbyTurnPos(chatID, first.position); err != nil {
+		klog.Error(err)
+		sendReplyMsg(bot, msg, "❌誤りです。ゲームを開始できませんでした。")
+		return
+	}
+	doShowLobby(bot, chatID)
+	newGame(bot, chatID)
+}
+
+// Skip the turn of a player that has gone quiet, so a play order without a time limit doesn't get stuck.
+func doSkip(bot *tg.BotAPI, msg *tg.Message) {
+	log.Println("Received skip command.")
+	chatID := msg.Chat.ID
+	turn := getLobbyTurn(chatID)
+	if turn == nil {
+		sendReplyMsg(bot, msg, "順番はありません。/join で参加して、/start で開始して下さい。")
+		return
+	}
+	if getIntSetting(chatID, settingTimeLimit) != 0 {
+		sendReplyMsg(bot, msg, "時間制限があるので、時間切れになると次の人の番になります。")
+		return
+	}
+	player := getPlayer(chatID, msg.From)
+	turnPlayer, _ := getPlayerByID(chatID, turn.userid)
+	if turn.userid == player.userid {
+		sendReplyMsg(bot, msg, "自分の番は飛ばせません。言葉を入力して下さい。")
+		return
+	}
+	if idle := time.Since(time.Unix(getLobbyTurnStarted(chatID), 0)); idle < lobbySkipIdle {
+		sendReplyMsg(bot, msg, fmt.Sprintf("%s様の番です。あと%sで /skip できます。", formatPlayerName(turnPlayer), formatDuration(int64((lobbySkipIdle-idle).Seconds()))))
+		return
+	}
+	// The turn passes on before the player is skipped, so the next player doesn't get skipped with them.
+	advanceLobbyTurn(chatID)
+	bot.Send(tg.NewMessage(chatID, fmt.Sprintf("⏭%s様の番を飛ばしました。", formatPlayerName(turnPlayer))))
+	lobbyPlayerMissedTurn(bot, chatID, turn.userid)
+	announceLobbyTurn(bot, chatID)
+}
+
+func doShowLobby(bot *tg.BotAPI, chatID int64) {
+	lobby := "<b>順番</b>\n＿＿＿＿＿＿＿＿＿＿＿"
+	turn := getLobbyTurn(chatID)
+	for index, lp := range getLobbyPlayers(chatID) {
+		player, _ := getPlayerByID(chatID, lp.userid)
+		lobby += fmt.Sprintf("\n%d. %s", index+1, html.EscapeString(formatPlayerName(player)))
+		if !lp.active() {
+			lobby += " (休み)"
+		} else if turn != nil && turn.userid == lp.userid {
+			lobby += " 👈"
+		}
+	}
+	if turn == nil {
+		lobby += "\n\n/join で参加して、/start で開始して下さい。"
+	}
+	msg := tg.NewMessage(chatID, lobby)
+	msg.ParseMode = tg.ModeHTML
+	bot.Send(msg)
+}
+
+// Get the active player whose turn it is, or nil if the chat has not started a play order.
+func getLobbyTurn(chatID int64) *lobbyPlayer {
+	turnpos, started := getLobbyTurnPos(chatID)
+	if !started {
+		return nil
+	}
+	var first *lobbyPlayer
+	for _, lp := range getLobbyPlayers(chatID) {
+		if !lp.active() || playerIsOut(chatID, lp.userid) {
+			continue
+		}
+		if lp.position >= turnpos {
+			return lp
+		}
+		if first == nil {
+			first = lp
+		}
+	}
+	// Go around to the start of the play order.
+	return first
+}
+
+// Pass the turn to the next active player in the play order.
+func advanceLobbyTurn(chatID int64) {
+	if turn := getLobbyTurn(chatID); turn != nil {
+		setLobbyTurnPos(chatID, turn.position+1)
+	}
+}
+
+// Stop taking turns in a fixed order once there are not enough active players left. Returns false if the play order was stopped.
+func checkLobbyPlayers(bot *tg.BotAPI, chatID int64) bool {
+	if _, started := getLobbyTurnPos(chatID); !started {
+		return false
+	}
+	numActive := 0
+	for _, lp := range getLobbyPlayers(chatID) {
+		if lp.active() {
+			numActive++
+		}
+	}
+	if numActive >= 2 {
+		return true
+	}
+	stopLobby(chatID)
+	clearTurnTimer(chatID)
+	bot.Send(tg.NewMessage(chatID, "プレーヤーが足りないので、順番はなくなりました。\n/join で参加して、/start で再開して下さい。"))
+	return false
+}
+
+// Skip the players that keep missing their turns, whether their time ran out or the other players skipped them.
+func lobbyPlayerMissedTurn(bot *tg.BotAPI, chatID int64, userID int64) {
+	lp := getLobbyPlayer(chatID, userID)
+	if lp == nil {
+		return
+	}
+	updateMissedTurns(chatID, userID, lp.missed+1)
+	if lp.missed+1 == maxMissedTurns {
+		player, _ := getPlayerByID(chatID, userID)
+		bot.Send(tg.NewMessage(chatID, fmt.Sprintf("%s様は%d回続けて答えなかったので、順番から外しました。/join で戻れます。", formatPlayerName(player), maxMissedTurns)))
+		checkLobbyPlayers(bot, chatID)
+	}
+}
+
+func announceLobbyTurn(bot *tg.BotAPI, chatID int64) {
+	turn := getLobbyTurn(chatID)
+	if turn == nil {
+		return
+	}
+	player, _ := getPlayerByID(chatID, turn.userid)
+	msg := tg.NewMessage(chatID, fmt.Sprintf("👉%s様の番です。", formatPlayerMention(player)))
+	msg.ParseMode = tg.ModeHTML
+	bot.Send(msg)
+}
+
+// Format the player name as a link that notifies the player, even if they don't have a username.
+func formatPlayerMention(player *playerEntry) string {
+	return fmt.Sprintf(`<a href="tg://user?id=%d">%s</a>`, player.userid, html.EscapeString(formatPlayerName(player)))
+}
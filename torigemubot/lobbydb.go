@@ -0,0 +1,89 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+const lobbiesTableName = "lobbies"
+const lobbyplayersTableName = "lobbyplayers"
+
+// Players that miss this many turns in a row are skipped until they /join again.
+const maxMissedTurns = 2
+
+// Without a time limit, the other players can /skip a turn after it has been idle this long.
+const lobbySkipIdle = 5 * time.Minute
+
+// A player that has joined the play order of a chat.
+type lobbyPlayer struct {
+	userid   int64
+	position int
+	missed   int
+}
+
+func (lp *lobbyPlayer) active() bool {
+	return lp.missed < maxMissedTurns
+}
+
+func getLobbyPlayers(chatID int64) []*lobbyPlayer {
+	players := make([]*lobbyPlayer, 0)
+	gamedb.MultiQuery(fmt.Sprintf("SELECT userid, position, missed FROM %s WHERE chatid = %d ORDER BY position", lobbyplayersTableName, chatID),
+		func(rows *sql.Rows) error {
+			player := &lobbyPlayer{}
+			rows.Scan(&player.userid, &player.position, &player.missed)
+			players = append(players, player)
+			return nil
+		})
+	return players
+}
+
+func getLobbyPlayer(chatID int64, userID int64) *lobbyPlayer {
+	player := &lobbyPlayer{
+		userid: userID,
+	}
+	if nil != gamedb.SingleQuery(fmt.Sprintf("SELECT position, missed FROM %s WHERE chatid = %d AND userid = %d", lobbyplayersTableName, chatID, userID),
+		&player.position, &player.missed) {
+		return nil
+	}
+	return player
+}
+
+func addLobbyPlayer(chatID int64, userID int64) error {
+	// New players join at the end of the play order.
+	return gamedb.Exec(fmt.Sprintf("INSERT INTO %s (chatid, userid, position, missed) VALUES (?, ?, (SELECT COALESCE(MAX(position), 0)+1 FROM %s WHERE chatid = ?), 0)", lobbyplayersTableName, lobbyplayersTableName),
+		chatID, userID, chatID)
+}
+
+func removeLobbyPlayer(chatID int64, userID int64) error {
+	return gamedb.Exec(fmt.Sprintf("DELETE FROM %s WHERE chatid = %d AND userid = %d", lobbyplayersTableName, chatID, userID))
+}
+
+func updateMissedTurns(chatID int64, userID int64, missed int) error {
+	return gamedb.Exec(fmt.Sprintf("UPDATE %s SET missed = %d WHERE chatid = %d AND userid = %d", lobbyplayersTableName, missed, chatID, userID))
+}
+
+// Get the position in the play order of the player whose turn it is. The second value is false if the chat has not started a play order.
+func getLobbyTurnPos(chatID int64) (int, bool) {
+	var started bool
+	var turnpos int
+	if nil != gamedb.SingleQuery(fmt.Sprintf("SELECT started, turnpos FROM %s WHERE chatid = %d", lobbiesTableName, chatID), &started, &turnpos) {
+		return 0, false
+	}
+	return turnpos, started
+}
+
+func setLobbyTurnPos(chatID int64, turnpos int) error {
+	return gamedb.Exec(fmt.Sprintf("INSERT OR REPLACE INTO %s (chatid, started, turnpos, turnstarted) VALUES (?, ?, ?, ?)", lobbiesTableName), chatID, true, turnpos, time.Now().Unix())
+}
+
+// Get the time that the current turn of the play order started.
+func getLobbyTurnStarted(chatID int64) int64 {
+	var turnstarted int64
+	gamedb.SingleQuery(fmt.Sprintf("SELECT turnstarted FROM %s WHERE chatid = %d", lobbiesTableName, chatID), &turnstarted)
+	return turnstarted
+}
+
+func stopLobby(chatID int64) error {
+	return gamedb.Exec(fmt.Sprintf("DELETE FROM %s WHERE chatid = %d", lobbiesTableName, chatID))
+}
@@ -8,18 +8,30 @@ import (
 	"k8s.io/klog"
 )
 
-var noturns *bool
-
 func main() {
 	token := flag.String("token", "Ask @BotFather", "telegram bot token")
 	debug := flag.Bool("debug", false, "Show debug information")
-	noturns = flag.Bool("noturns", false, "Don't take turns")
+	noturns := flag.Bool("noturns", false, "Don't take turns unless a chat's settings say otherwise")
+	rebuildscores := flag.Bool("rebuildscores", false, "Rebuild the players' scores from the score events ledger and exit")
 	flag.Parse()
 
 	klog.InitFlags(nil)
+	if *rebuildscores {
+		if err := initgameDb(); err != nil {
+			log.Fatalf("could not initialize database: %v", err)
+		}
+		if err := rebuildScores(); err != nil {
+			log.Fatalf("could not rebuild the scores: %v", err)
+		}
+		log.Print("Rebuilt the scores from the score events ledger.")
+		return
+	}
 	if *token == "Ask @BotFather" {
 		log.Fatal("token flag required. Go ask @BotFather.")
 	}
+	if *noturns {
+		findRuleSetting(settingTurns).defaultValue = "off"
+	}
 
 	log.Print("Connecting...")
 	bot, err := tg.NewBotAPI(*token)
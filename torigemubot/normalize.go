@@ -0,0 +1,52 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Characters that are kept in a submission, besides letters. They are needed for romaji and chosen readings.
+const keptWordSymbols = "'-()"
+
+// A submission that is a word: Japanese, or romaji if the chat plays in romaji.
+func isWordInput(word string, romaji bool) bool {
+	return len(word) > 0 && (kanjiExp.FindString(word) == word || romaji && isRomaji(word))
+}
+
+// Clean up a submission before it is looked up. The width of the characters is folded (i.e., ｶﾀｶﾅ → カタカナ),
+// whitespace, punctuation and emoji are removed, and kana iteration marks are replaced with the kana they repeat (i.e., すゞめ → すずめ).
+func normalizeWord(text string) string {
+	normalized := make([]rune, 0)
+	for _, k := range norm.NFKC.String(text) {
+		if !unicode.IsLetter(k) && !unicode.IsMark(k) && !strings.ContainsRune(keptWordSymbols, k) {
+			continue
+		}
+		normalized = append(normalized, k)
+	}
+	return replaceIterationMarks(string(normalized))
+}
+
+// Replace the kana iteration marks (ゝゞヽヾ) with the kana that they repeat.
+func replaceIterationMarks(word string) string {
+	replaced := make([]rune, 0)
+	for _, k := range word {
+		if len(replaced) > 0 {
+			last := []rune(plainKana(string(replaced[len(replaced)-1])))[0]
+			switch k {
+			case 'ゝ', 'ヽ':
+				k = last
+			case 'ゞ', 'ヾ':
+				// The voiced kana comes right after its plain kana (i.e., す, ず).
+				if plainKana(string(last+1)) == string(last) {
+					k = last + 1
+				} else {
+					k = last
+				}
+			}
+		}
+		replaced = append(replaced, k)
+	}
+	return string(replaced)
+}
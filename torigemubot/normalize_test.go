@@ -0,0 +1,69 @@
+package main
+
+import "testing"
+
+func TestNormalizeWord(t *testing.T) {
+	tests := []struct {
+		word       string
+		normalized string
+	}{
+		{"すし", "すし"},
+		{" すし。", "すし"},
+		{"す　し", "すし"},
+		{"すし🍣", "すし"},
+		{"ｶﾀｶﾅ", "カタカナ"},
+		{"ｓｕｓｈｉ", "sushi"},
+		{"kon'ya", "kon'ya"},
+		{"上手（うわて）", "上手(うわて)"},
+		{"すゞめ", "すずめ"},
+		{"ハヽ", "ハハ"},
+		{"", ""},
+	}
+	for _, test := range tests {
+		if normalized := normalizeWord(test.word); normalized != test.normalized {
+			t.Errorf("normalizeWord(%q) = %q; want %q", test.word, normalized, test.normalized)
+		}
+	}
+}
+
+func TestReplaceIterationMarks(t *testing.T) {
+	tests := []struct {
+		word     string
+		replaced string
+	}{
+		{"こゝろ", "こころ"},
+		{"いすゞ", "いすず"},
+		{"ぶゞ", "ぶぶ"},
+		{"ぷゝ", "ぷふ"},
+		{"あゞ", "ああ"},
+		{"バヽ", "バハ"},
+		{"ハヾ", "ハバ"},
+		{"ゝ", "ゝ"},
+		{"すし", "すし"},
+	}
+	for _, test := range tests {
+		if replaced := replaceIterationMarks(test.word); replaced != test.replaced {
+			t.Errorf("replaceIterationMarks(%q) = %q; want %q", test.word, replaced, test.replaced)
+		}
+	}
+}
+
+func TestIsWordInput(t *testing.T) {
+	tests := []struct {
+		word   string
+		romaji bool
+		want   bool
+	}{
+		{"すし", false, true},
+		{"寿司", false, true},
+		{"sushi", false, false},
+		{"sushi", true, true},
+		{"すしsushi", true, false},
+		{"", true, false},
+	}
+	for _, test := range tests {
+		if got := isWordInput(test.word, test.romaji); got != test.want {
+			t.Errorf("isWordInput(%q, %v) = %v; want %v", test.word, test.romaji, got, test.want)
+		}
+	}
+}
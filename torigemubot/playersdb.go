@@ -9,6 +9,7 @@ import (
 )
 
 const playersTableName = "players"
+const updatePlayerScoreSavePoint = "UpdatePlayerScore"
 
 func getPlayer(chatID int64, user *tg.User) *playerEntry {
 	var update = false
@@ -41,21 +42,21 @@ func getPlayerByID(chatID int64, userid int64) (*playerEntry, error) {
 		chatid: chatID,
 		userid: userid,
 	}
-	err := gamedb.SingleQuery(fmt.Sprintf("SELECT firstname, lastname, username, nickname, score, numwords FROM %s WHERE chatid = %d AND userid = %d",
+	err := gamedb.SingleQuery(fmt.Sprintf("SELECT firstname, lastname, username, nickname, score, numwords, wins FROM %s WHERE chatid = %d AND userid = %d",
 		playersTableName, player.chatid, player.userid),
-		&player.firstname, &player.lastname, &player.username, &player.nickname, &player.score, &player.numWords)
+		&player.firstname, &player.lastname, &player.username, &player.nickname, &player.score, &player.numWords, &player.wins)
 	return player, err
 }
 
 func getPlayers(chatID int64) []*playerEntry {
 	players := make(playerList, 0)
 	// Sort by score ranking.
-	gamedb.MultiQuery(fmt.Sprintf("SELECT userid, firstname, lastname, username, nickname, score, numwords FROM %s WHERE chatid = %d ORDER BY score DESC", playersTableName, chatID),
+	gamedb.MultiQuery(fmt.Sprintf("SELECT userid, firstname, lastname, username, nickname, score, numwords, wins FROM %s WHERE chatid = %d ORDER BY score DESC", playersTableName, chatID),
 		func(rows *sql.Rows) error {
 			player := &playerEntry{
 				chatid: chatID,
 			}
-			rows.Scan(&player.userid, &player.firstname, &player.lastname, &player.username, &player.nickname, &player.score, &player.numWords)
+			rows.Scan(&player.userid, &player.firstname, &player.lastname, &player.username, &player.nickname, &player.score, &player.numWords, &player.wins)
 			players = append(players, player)
 			return nil
 		})
@@ -72,9 +73,15 @@ func savePlayer(player *playerEntry) error {
 		player.firstname, player.lastname, player.username, player.nickname, player.score, player.numWords, player.chatid, player.userid)
 }
 
-func updatePlayerScore(chatID int64, userid int64, scoreUpdate int) error {
-	return gamedb.Exec(fmt.Sprintf("UPDATE %s SET score = (SELECT score+%d FROM %s WHERE chatid = %d AND userid = %d) WHERE chatid = %d AND userid = %d",
-		playersTableName, scoreUpdate, playersTableName, chatID, userid, chatID, userid))
+// Every change of a score is recorded in the score events ledger, with the reason for it and the word it was for.
+func updatePlayerScore(chatID int64, userid int64, scoreUpdate int, reason string, word string) error {
+	return gamedb.ExecWithSavePoint(updatePlayerScoreSavePoint, func() error {
+		if err := addScoreEvent(chatID, userid, scoreUpdate, reason, word); err != nil {
+			return err
+		}
+		return gamedb.Exec(fmt.Sprintf("UPDATE %s SET score = (SELECT score+%d FROM %s WHERE chatid = %d AND userid = %d) WHERE chatid = %d AND userid = %d",
+			playersTableName, scoreUpdate, playersTableName, chatID, userid, chatID, userid))
+	})
 }
 
 func updatePlayerWords(chatID int64, userid int64, wordsUpdate int) error {
@@ -82,6 +89,26 @@ func updatePlayerWords(chatID int64, userid int64, wordsUpdate int) error {
 		playersTableName, wordsUpdate, playersTableName, chatID, userid, chatID, userid))
 }
 
+func updatePlayerWins(chatID int64, userid int64, winsUpdate int) error {
+	return gamedb.Exec(fmt.Sprintf("UPDATE %s SET wins = (SELECT wins+%d FROM %s WHERE chatid = %d AND userid = %d) WHERE chatid = %d AND userid = %d",
+		playersTableName, winsUpdate, playersTableName, chatID, userid, chatID, userid))
+}
+
+func updatePlayerStreak(chatID int64, userid int64, streak int) error {
+	return gamedb.Exec(fmt.Sprintf("UPDATE %s SET streak = %d WHERE chatid = %d AND userid = %d", playersTableName, streak, chatID, userid))
+}
+
+// A new game starts every player's streak over.
+func clearPlayerStreaks(chatID int64) error {
+	return gamedb.Exec(fmt.Sprintf("UPDATE %s SET streak = 0 WHERE chatid = %d", playersTableName, chatID))
+}
+
+func getPlayerStreak(chatID int64, userid int64) int {
+	var streak int
+	gamedb.SingleQuery(fmt.Sprintf("SELECT streak FROM %s WHERE chatid = %d AND userid = %d", playersTableName, chatID, userid), &streak)
+	return streak
+}
+
 func nickNameInUse(chatID int64, nickName string) bool {
 	return nil == gamedb.SingleQuery(fmt.Sprintf("SELECT userid FROM %s WHERE chatid = %d and nickname = '%s'", playersTableName, chatID, nickName))
 }
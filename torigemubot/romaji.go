@@ -0,0 +1,115 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Romaji syllables mapped to hiragana. Hepburn, plus the common Kunrei-shiki and keyboard variants (i.e., shi/si, tsu/tu, ji/zi).
+var romajiMap = map[string]string{
+	"a": "あ", "i": "い", "u": "う", "e": "え", "o": "お",
+	"ka": "か", "ki": "き", "ku": "く", "ke": "け", "ko": "こ",
+	"kya": "きゃ", "kyu": "きゅ", "kyo": "きょ",
+	"ga": "が", "gi": "ぎ", "gu": "ぐ", "ge": "げ", "go": "ご",
+	"gya": "ぎゃ", "gyu": "ぎゅ", "gyo": "ぎょ",
+	"sa": "さ", "shi": "し", "si": "し", "su": "す", "se": "せ", "so": "そ",
+	"sha": "しゃ", "shu": "しゅ", "sho": "しょ", "she": "しぇ",
+	"sya": "しゃ", "syu": "しゅ", "syo": "しょ",
+	"za": "ざ", "ji": "じ", "zi": "じ", "zu": "ず", "ze": "ぜ", "zo": "ぞ",
+	"ja": "じゃ", "ju": "じゅ", "jo": "じょ", "je": "じぇ",
+	"jya": "じゃ", "jyu": "じゅ", "jyo": "じょ",
+	"zya": "じゃ", "zyu": "じゅ", "zyo": "じょ",
+	"ta": "た", "chi": "ち", "ti": "ち", "tsu": "つ", "tu": "つ", "te": "て", "to": "と",
+	"cha": "ちゃ", "chu": "ちゅ", "cho": "ちょ", "che": "ちぇ",
+	"cya": "ちゃ", "cyu": "ちゅ", "cyo": "ちょ",
+	"tya": "ちゃ", "tyu": "ちゅ", "tyo": "ちょ",
+	"da": "だ", "di": "ぢ", "du": "づ", "dzu": "づ", "de": "で", "do": "ど",
+	"dya": "ぢゃ", "dyu": "ぢゅ", "dyo": "ぢょ",
+	"na": "な", "ni": "に", "nu": "ぬ", "ne": "ね", "no": "の",
+	"nya": "にゃ", "nyu": "にゅ", "nyo": "にょ",
+	"ha": "は", "hi": "ひ", "fu": "ふ", "hu": "ふ", "he": "へ", "ho": "ほ",
+	"hya": "ひゃ", "hyu": "ひゅ", "hyo": "ひょ",
+	"fa": "ふぁ", "fi": "ふぃ", "fe": "ふぇ", "fo": "ふぉ",
+	"ba": "ば", "bi": "び", "bu": "ぶ", "be": "べ", "bo": "ぼ",
+	"bya": "びゃ", "byu": "びゅ", "byo": "びょ",
+	"pa": "ぱ", "pi": "ぴ", "pu": "ぷ", "pe": "ぺ", "po": "ぽ",
+	"pya": "ぴゃ", "pyu": "ぴゅ", "pyo": "ぴょ",
+	"ma": "ま", "mi": "み", "mu": "む", "me": "め", "mo": "も",
+	"mya": "みゃ", "myu": "みゅ", "myo": "みょ",
+	"ya": "や", "yu": "ゆ", "yo": "よ",
+	"ra": "ら", "ri": "り", "ru": "る", "re": "れ", "ro": "ろ",
+	"rya": "りゃ", "ryu": "りゅ", "ryo": "りょ",
+	"la": "ら", "li": "り", "lu": "る", "le": "れ", "lo": "ろ",
+	"wa": "わ", "wi": "うぃ", "we": "うぇ", "wo": "を",
+	"va": "ゔぁ", "vi": "ゔぃ", "vu": "ゔ", "ve": "ゔぇ", "vo": "ゔぉ",
+	"xa": "ぁ", "xi": "ぃ", "xu": "ぅ", "xe": "ぇ", "xo": "ぉ",
+	"xya": "ゃ", "xyu": "ゅ", "xyo": "ょ", "xtsu": "っ", "xtu": "っ",
+	"nn": "ん", "n'": "ん",
+	"-": "ー",
+}
+
+// The Hepburn long vowels with a macron or circumflex.
+var romajiLongVowels = strings.NewReplacer(
+	"ā", "aa", "ī", "ii", "ū", "uu", "ē", "ee", "ō", "ou",
+	"â", "aa", "î", "ii", "û", "uu", "ê", "ee", "ô", "ou",
+)
+
+var romajiExp = regexp.MustCompile(`^[a-zA-Zāīūēōâîûêô'\-]+$`)
+
+// The longest romaji syllable in the map.
+const maxRomajiLength = 4
+
+// Check whether the submission is written in romaji.
+func isRomaji(word string) bool {
+	return romajiExp.MatchString(word)
+}
+
+// Convert the romaji to hiragana (i.e., sushi → すし, kitte → きって, shinbun → しんぶん).
+// The second value is false if any of the romaji could not be converted.
+func romajiToKana(romaji string) (string, bool) {
+	romaji = romajiLongVowels.Replace(strings.ToLower(romaji))
+	kana := ""
+	for len(romaji) > 0 {
+		// A doubled consonant is a small 'っ' (i.e., kitte → きって), except for 'mm', which is 'ん' (i.e., sammai → さんまい).
+		if len(romaji) > 1 && romaji[0] == romaji[1] && !strings.ContainsRune("aiueonm'-", rune(romaji[0])) {
+			kana += "っ"
+			romaji = romaji[1:]
+			continue
+		}
+		// Hepburn writes 'っち' as 'tch' (i.e., matcha → まっちゃ).
+		if strings.HasPrefix(romaji, "tch") {
+			kana += "っ"
+			romaji = romaji[1:]
+			continue
+		}
+		// A doubled 'n' before a vowel is 'ん' and a na-row kana (i.e., onna → おんな), but otherwise it is just 'ん'.
+		if strings.HasPrefix(romaji, "nn") && len(romaji) > 2 && strings.ContainsRune("aiueoy", rune(romaji[2])) {
+			kana += "ん"
+			romaji = romaji[1:]
+			continue
+		}
+		// Hepburn writes 'ん' as 'n' before a consonant or at the end, and as 'm' before b, p and m (i.e., shimbun → しんぶん).
+		if romaji[0] == 'n' && (len(romaji) == 1 || !strings.ContainsRune("aiueoyn'", rune(romaji[1]))) ||
+			romaji[0] == 'm' && len(romaji) > 1 && strings.ContainsRune("bpm", rune(romaji[1])) {
+			kana += "ん"
+			romaji = romaji[1:]
+			continue
+		}
+		matched := false
+		for length := maxRomajiLength; length > 0; length-- {
+			if length > len(romaji) {
+				continue
+			}
+			if k, ok := romajiMap[romaji[:length]]; ok {
+				kana += k
+				romaji = romaji[length:]
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return kana, false
+		}
+	}
+	return kana, true
+}
@@ -0,0 +1,74 @@
+package main
+
+import "testing"
+
+func TestRomajiToKana(t *testing.T) {
+	tests := []struct {
+		romaji string
+		kana   string
+		ok     bool
+	}{
+		// Hepburn and Kunrei-shiki variants.
+		{"sushi", "すし", true},
+		{"susi", "すし", true},
+		{"tsukue", "つくえ", true},
+		{"tukue", "つくえ", true},
+		{"chizu", "ちず", true},
+		{"tizu", "ちず", true},
+		{"fuji", "ふじ", true},
+		{"huzi", "ふじ", true},
+		{"jisho", "じしょ", true},
+		{"zisyo", "じしょ", true},
+		// Doubled consonants are a small っ.
+		{"kitte", "きって", true},
+		{"zasshi", "ざっし", true},
+		{"matcha", "まっちゃ", true},
+		{"kippu", "きっぷ", true},
+		// n, nn and m before b, p and m are ん.
+		{"hon", "ほん", true},
+		{"shinbun", "しんぶん", true},
+		{"shimbun", "しんぶん", true},
+		{"tempura", "てんぷら", true},
+		{"sammai", "さんまい", true},
+		{"onna", "おんな", true},
+		{"konnichiwa", "こんにちわ", true},
+		{"kon'ya", "こんや", true},
+		{"konya", "こにゃ", true},
+		{"nn", "ん", true},
+		// Long vowels.
+		{"toukyou", "とうきょう", true},
+		{"tōkyō", "とうきょう", true},
+		{"tôkyô", "とうきょう", true},
+		{"okaasan", "おかあさん", true},
+		{"ra-men", "らーめん", true},
+		{"Sushi", "すし", true},
+		// Romaji that isn't Japanese.
+		{"qwerty", "", false},
+		{"kx", "", false},
+	}
+	for _, test := range tests {
+		kana, ok := romajiToKana(test.romaji)
+		if ok != test.ok || ok && kana != test.kana {
+			t.Errorf("romajiToKana(%q) = %q, %v; want %q, %v", test.romaji, kana, ok, test.kana, test.ok)
+		}
+	}
+}
+
+func TestIsRomaji(t *testing.T) {
+	tests := []struct {
+		word string
+		want bool
+	}{
+		{"sushi", true},
+		{"kon'ya", true},
+		{"tōkyō", true},
+		{"すし", false},
+		{"sushiすし", false},
+		{"", false},
+	}
+	for _, test := range tests {
+		if got := isRomaji(test.word); got != test.want {
+			t.Errorf("isRomaji(%q) = %v; want %v", test.word, got, test.want)
+		}
+	}
+}
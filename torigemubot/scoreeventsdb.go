@@ -0,0 +1,78 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+const scoreeventsTableName = "scoreevents"
+
+// The reasons that a score changes.
+const (
+	scoreReasonBalance    = "balance"
+	scoreReasonFirstWord  = "firstword"
+	scoreReasonWord       = "word"
+	scoreReasonBonus      = "bonus"
+	scoreReasonLost       = "lost"
+	scoreReasonWin        = "win"
+	scoreReasonAddWord    = "addword"
+	scoreReasonRemoveWord = "removeword"
+)
+
+// A change of a player's score. The ledger is only ever appended to.
+type scoreEvent struct {
+	eventid int64
+	userid  int64
+	delta   int
+	reason  string
+	word    string
+	gameid  int64
+	time    int64
+}
+
+func addScoreEvent(chatID int64, userID int64, delta int, reason string, word string) error {
+	return gamedb.Exec(fmt.Sprintf("INSERT INTO %s (chatid, userid, delta, reason, word, gameid, time) VALUES (?, ?, ?, ?, ?, ?, ?)", scoreeventsTableName),
+		chatID, userID, delta, reason, word, getGameID(chatID), time.Now().Unix())
+}
+
+// Get the latest score events of the chat, with the newest first. A user ID of zero gets the events of every player.
+func getScoreEvents(chatID int64, userID int64, limit int) []*scoreEvent {
+	events := make([]*scoreEvent, 0)
+	where := fmt.Sprintf("chatid = %d", chatID)
+	if userID != 0 {
+		where += fmt.Sprintf(" AND userid = %d", userID)
+	}
+	gamedb.MultiQuery(fmt.Sprintf("SELECT eventid, userid, delta, reason, word, gameid, time FROM %s WHERE %s ORDER BY eventid DESC LIMIT %d", scoreeventsTableName, where, limit),
+		func(rows *sql.Rows) error {
+			event := &scoreEvent{}
+			rows.Scan(&event.eventid, &event.userid, &event.delta, &event.reason, &event.word, &event.gameid, &event.time)
+			events = append(events, event)
+			return nil
+		})
+	return events
+}
+
+// Sum the score events of each player of the chat by their reason.
+func getScoreComponents(chatID int64) map[int64]map[string]int {
+	components := make(map[int64]map[string]int)
+	gamedb.MultiQuery(fmt.Sprintf("SELECT userid, reason, SUM(delta) FROM %s WHERE chatid = %d GROUP BY userid, reason", scoreeventsTableName, chatID),
+		func(rows *sql.Rows) error {
+			var userID int64
+			var reason string
+			var delta int
+			rows.Scan(&userID, &reason, &delta)
+			if components[userID] == nil {
+				components[userID] = make(map[string]int)
+			}
+			components[userID][reason] = delta
+			return nil
+		})
+	return components
+}
+
+// Recalculate every player's score from the score events ledger.
+func rebuildScores() error {
+	return gamedb.Exec(fmt.Sprintf("UPDATE %s SET score = COALESCE((SELECT SUM(delta) FROM %s WHERE %s.chatid = %s.chatid AND %s.userid = %s.userid), 0)",
+		playersTableName, scoreeventsTableName, scoreeventsTableName, playersTableName, scoreeventsTableName, playersTableName))
+}
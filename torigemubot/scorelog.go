@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"log"
+	"strings"
+	"time"
+
+	tg "github.com/semog/go-bot-api/v5"
+)
+
+const maxScoreLogEvents = 20
+
+var scoreReasonDisplays = map[string]string{
+	scoreReasonBalance:    "以前の得点",
+	scoreReasonFirstWord:  "最初の言葉",
+	scoreReasonWord:       "言葉",
+	scoreReasonBonus:      "ボーナス",
+	scoreReasonLost:       "負け",
+	scoreReasonWin:        "優勝",
+	scoreReasonAddWord:    "言葉を追加",
+	scoreReasonRemoveWord: "言葉を削除",
+}
+
+// The parts of a score that /scores shows, and the reasons of the score events that make up each part.
+var scoreComponents = []struct {
+	label   string
+	reasons []string
+}{
+	{label: "言葉", reasons: []string{scoreReasonFirstWord, scoreReasonWord}},
+	{label: "ボーナス", reasons: []string{scoreReasonBonus}},
+	{label: "優勝", reasons: []string{scoreReasonWin}},
+	{label: "負け", reasons: []string{scoreReasonLost}},
+	{label: "追加した言葉", reasons: []string{scoreReasonAddWord, scoreReasonRemoveWord}},
+	{label: "以前の得点", reasons: []string{scoreReasonBalance}},
+}
+
+// Show how a player's score was made up, such as 言葉+12・ボーナス+3・負け-3.
+func getScoreComponentsDisplay(reasonPts map[string]int) string {
+	parts := make([]string, 0)
+	for _, component := range scoreComponents {
+		pts := 0
+		for _, reason := range component.reasons {
+			pts += reasonPts[reason]
+		}
+		if pts != 0 {
+			parts = append(parts, fmt.Sprintf("%s%+d", component.label, pts))
+		}
+	}
+	return strings.Join(parts, "・")
+}
+
+// Show the latest score changes of the chat, or of one player (i.e., /scorelog さくら).
+func doScoreLog(bot *tg.BotAPI, msg *tg.Message) {
+	log.Println("Received scorelog command.")
+	chatID := msg.Chat.ID
+	players := getPlayers(chatID)
+	var userID int64
+	title := "<b>得点の記録</b>"
+	if name := strings.TrimSpace(msg.CommandArguments()); len(name) > 0 {
+		player := findPlayerByName(players, name)
+		if player == nil {
+			sendReplyMsg(bot, msg, fmt.Sprintf("「%s」というプレーヤーはいません。\nm(_ _)m", name))
+			return
+		}
+		userID = player.userid
+		title = fmt.Sprintf("<b>%s様の得点の記録</b>", html.EscapeString(formatPlayerName(player)))
+	}
+	events := getScoreEvents(chatID, userID, maxScoreLogEvents)
+	if len(events) == 0 {
+		sendReplyMsg(bot, msg, "得点の記録はまだありません。")
+		return
+	}
+	playerNames := make(map[int64]string)
+	for _, player := range players {
+		playerNames[player.userid] = formatPlayerName(player)
+	}
+	scoreLog := title + "\n＿＿＿＿＿＿＿＿＿＿＿"
+	for _, event := range events {
+		scoreLog += fmt.Sprintf("\n%s %+d %s", time.Unix(event.time, 0).Format("01/02 15:04"), event.delta, scoreReasonDisplays[event.reason])
+		if len(event.word) > 0 {
+			scoreLog += fmt.Sprintf("「%s」", html.EscapeString(event.word))
+		}
+		if event.gameid != 0 {
+			scoreLog += fmt.Sprintf(" #%d", event.gameid)
+		}
+		if name, ok := playerNames[event.userid]; ok && userID == 0 {
+			scoreLog += " " + html.EscapeString(name)
+		}
+	}
+	reply := tg.NewMessage(chatID, scoreLog)
+	reply.ParseMode = tg.ModeHTML
+	bot.Send(reply)
+}
+
+// Find a player by their nickname, or else their username, or else their first name.
+// The players are in score order, so the same name always finds the same player.
+func findPlayerByName(players []*playerEntry, name string) *playerEntry {
+	name = strings.TrimPrefix(name, "@")
+	nameOf := []func(player *playerEntry) string{
+		func(player *playerEntry) string { return player.nickname },
+		func(player *playerEntry) string { return player.username },
+		func(player *playerEntry) string { return player.firstname },
+		formatPlayerName,
+	}
+	for _, playerName := range nameOf {
+		for _, player := range players {
+			if n := playerName(player); len(n) > 0 && strings.EqualFold(n, name) {
+				return player
+			}
+		}
+	}
+	return nil
+}
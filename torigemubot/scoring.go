@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Values of the scoring rule.
+const (
+	scoringKanji  = "kanji"
+	scoringMorae  = "morae"
+	scoringRarity = "rarity"
+	scoringFlat   = "flat"
+)
+
+// A way of scoring the words of a game. Each chat picks one with the scoring setting.
+type scoringPolicy struct {
+	name        string
+	description string
+	// Get the points of a valid word, and the breakdown of how they were scored.
+	// The base points are the points of the word for the game mode, which are based on its kanji.
+	score func(theWord string, reading string, basePts int) (int, string)
+}
+
+// The JMdict misc tags of words that are hard to come up with, and the extra points they are worth.
+var rarityTagPts = map[string]int{
+	"arch": 3,
+	"obs":  3,
+	"obsc": 2,
+	"rare": 2,
+	"rK":   1,
+	"ok":   1,
+}
+
+// The frequency rank of a word is 1 (most common) to 48, or 0 if the word is in none of the JMdict frequency lists.
+// Less common words are worth more, so players are rewarded for digging up rare words instead of only hard kanji.
+type rarityTier struct {
+	maxRank int
+	pts     int
+	label   string
+}
+
+// The tiers, from the most common words. Unranked words are worth the most.
+var rarityTiers = []rarityTier{
+	{maxRank: 12, pts: 0, label: "よく使う"},
+	{maxRank: 24, pts: 1, label: "時々使う"},
+	{maxRank: 48, pts: 2, label: "あまり使わない"},
+}
+
+const unrankedRarityPts = 3
+
+var scoringPolicies = []*scoringPolicy{
+	{name: scoringKanji, description: "漢字の難しさ", score: scoreKanji},
+	{name: scoringMorae, description: "拍数", score: scoreMorae},
+	{name: scoringRarity, description: "言葉の珍しさ", score: scoreRarity},
+	{name: scoringFlat, description: "一律1得点", score: scoreFlat},
+}
+
+func findScoringPolicy(name string) *scoringPolicy {
+	for _, policy := range scoringPolicies {
+		if policy.name == name {
+			return policy
+		}
+	}
+	return scoringPolicies[0]
+}
+
+func getScoringPolicyNames() []string {
+	names := make([]string, 0)
+	for _, policy := range scoringPolicies {
+		names = append(names, policy.name)
+	}
+	return names
+}
+
+// Score the word with the chat's scoring policy.
+func scoreWord(chatID int64, theWord string, reading string, basePts int) (int, string) {
+	return findScoringPolicy(getSetting(chatID, settingScoring)).score(theWord, reading, basePts)
+}
+
+// The points of the word's kanji, as the dictionary and the game mode score them (i.e., 学校 → 学1・校2).
+func scoreKanji(theWord string, reading string, basePts int) (int, string) {
+	breakdown := make([]string, 0)
+	for _, k := range theWord {
+		if isKanji(k) {
+			breakdown = append(breakdown, fmt.Sprintf("%c%d", k, getKanjiPoints(string(k))))
+		}
+	}
+	if len(breakdown) == 0 {
+		return basePts, "仮名"
+	}
+	return basePts, strings.Join(breakdown, "・")
+}
+
+// Every mora of the longest reading is worth a point (i.e., じてんしゃ → 4).
+func scoreMorae(theWord string, reading string, basePts int) (int, string) {
+	morae := countMorae(getLongestReading(reading))
+	if morae == 0 {
+		return basePts, ""
+	}
+	return morae, fmt.Sprintf("%d拍", morae)
+}
+
+// Words that are uncommon, archaic, obscure or rare are worth more than everyday words.
+func scoreRarity(theWord string, reading string, basePts int) (int, string) {
+	pts := 1
+	breakdown := "普通"
+	if found, rank := lookupFreqRank(theWord); found {
+		pts, breakdown = 1+unrankedRarityPts, fmt.Sprintf("珍しい+%d", unrankedRarityPts)
+		for _, tier := range rarityTiers {
+			if rank > 0 && rank <= tier.maxRank {
+				pts, breakdown = 1+tier.pts, tier.label
+				if tier.pts > 0 {
+					breakdown += fmt.Sprintf("+%d", tier.pts)
+				}
+				break
+			}
+		}
+	}
+	for _, tag := range getWordTags(theWord, "misc") {
+		if tagPts, ok := rarityTagPts[tag]; ok && 1+tagPts > pts {
+			pts = 1 + tagPts
+			breakdown = fmt.Sprintf("%s+%d", tag, tagPts)
+		}
+	}
+	return pts, breakdown
+}
+
+func scoreFlat(theWord string, reading string, basePts int) (int, string) {
+	return 1, ""
+}
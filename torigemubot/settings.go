@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	tg "github.com/semog/go-bot-api/v5"
+	"k8s.io/klog"
+)
+
+const settingsCallbackPrefix = "settings:"
+const notAdminMsg = "❌設定を変更できるのはチャットの管理者だけです。"
+
+// Show the chat's house rules, or change one with "/settings name value".
+func doSettings(bot *tg.BotAPI, msg *tg.Message) {
+	log.Println("Received settings command.")
+	args := strings.Fields(msg.CommandArguments())
+	if len(args) == 0 {
+		reply := tg.NewMessage(msg.Chat.ID, getSettingsDisplay(msg.Chat.ID))
+		reply.ParseMode = tg.ModeMarkdown
+		reply.ReplyMarkup = getSettingsKeyboard(msg.Chat.ID)
+		bot.Send(reply)
+		return
+	}
+	rs := findRuleSetting(strings.ToLower(args[0]))
+	if rs == nil {
+		sendReplyMsg(bot, msg, fmt.Sprintf("❌設定がありません: %s", args[0]))
+		return
+	}
+	if len(rs.choices) == 0 {
+		// Settings without fixed choices are changed by the command with the same name.
+		sendReplyMsg(bot, msg, fmt.Sprintf("%s (%s): %s\n/%s で変更して下さい。", rs.description, rs.name, getSetting(msg.Chat.ID, rs.name), rs.name))
+		return
+	}
+	if len(args) < 2 || !rs.validChoice(strings.ToLower(args[1])) {
+		sendReplyMsg(bot, msg, fmt.Sprintf("%s (%s): %s\n選択肢: %s", rs.description, rs.name, getSetting(msg.Chat.ID, rs.name), strings.Join(rs.choices, ", ")))
+		return
+	}
+	if !isChatAdmin(bot, msg.Chat, msg.From) {
+		sendReplyMsg(bot, msg, notAdminMsg)
+		return
+	}
+	value := strings.ToLower(args[1])
+	oldValue := getSetting(msg.Chat.ID, rs.name)
+	if err := setSetting(msg.Chat.ID, rs.name, value); err != nil {
+		klog.Error(err)
+		sendReplyMsg(bot, msg, fmt.Sprintf("❌誤りです。設定を変更できませんでした：　%s", rs.name))
+		return
+	}
+	sendReplyMsg(bot, msg, fmt.Sprintf("設定を変更しました：　%s = %s", rs.description, value))
+	settingChanged(bot, msg.Chat.ID, rs.name, oldValue, value)
+}
+
+// Anyone may change the settings of a private chat, but only the administrators may change a group's.
+func isChatAdmin(bot *tg.BotAPI, chat *tg.Chat, user *tg.User) bool {
+	if chat == nil || user == nil {
+		return false
+	}
+	if chat.IsPrivate() {
+		return true
+	}
+	member, err := bot.GetChatMember(tg.GetChatMemberConfig{ChatConfigWithUser: tg.ChatConfigWithUser{ChatID: chat.ID, UserID: user.ID}})
+	if err != nil {
+		klog.Error(err)
+		return false
+	}
+	return member.IsCreator() || member.IsAdministrator()
+}
+
+// Changing the game mode starts a new game, because the chain was made under the rules of the old mode.
+func settingChanged(bot *tg.BotAPI, chatID int64, name string, oldValue string, value string) {
+	if name == settingMode && oldValue != value {
+		newGame(bot, chatID)
+	}
+}
+
+// Each button of the settings keyboard cycles to the next choice of its setting.
+func doSettingsCallback(bot *tg.BotAPI, query *tg.CallbackQuery) {
+	log.Println("Received settings callback.")
+	rs := findRuleSetting(strings.TrimPrefix(query.Data, settingsCallbackPrefix))
+	if rs == nil || len(rs.choices) == 0 || query.Message == nil {
+		bot.Request(tg.NewCallback(query.ID, "❌設定を変更できませんでした。"))
+		return
+	}
+	if !isChatAdmin(bot, query.Message.Chat, query.From) {
+		bot.Request(tg.NewCallback(query.ID, notAdminMsg))
+		return
+	}
+	chatID := query.Message.Chat.ID
+	oldValue := getSetting(chatID, rs.name)
+	value := rs.nextChoice(oldValue)
+	if err := setSetting(chatID, rs.name, value); err != nil {
+		klog.Error(err)
+		bot.Request(tg.NewCallback(query.ID, "❌設定を変更できませんでした。"))
+		return
+	}
+	edit := tg.NewEditMessageTextAndMarkup(chatID, query.Message.MessageID, getSettingsDisplay(chatID), getSettingsKeyboard(chatID))
+	edit.ParseMode = tg.ModeMarkdown
+	bot.Send(edit)
+	bot.Request(tg.NewCallback(query.ID, fmt.Sprintf("%s = %s", rs.description, value)))
+	settingChanged(bot, chatID, rs.name, oldValue, value)
+}
+
+func getSettingsDisplay(chatID int64) string {
+	settings := "*ゲームの設定*\n＿＿＿＿＿＿＿＿＿＿＿"
+	for _, rs := range ruleSettings {
+		settings += fmt.Sprintf("\n%s (%s): %s", rs.description, rs.name, getSetting(chatID, rs.name))
+	}
+	return settings
+}
+
+func getSettingsKeyboard(chatID int64) tg.InlineKeyboardMarkup {
+	rows := make([][]tg.InlineKeyboardButton, 0)
+	for _, rs := range ruleSettings {
+		if len(rs.choices) == 0 {
+			continue
+		}
+		rows = append(rows, tg.NewInlineKeyboardRow(
+			tg.NewInlineKeyboardButtonData(fmt.Sprintf("%s: %s", rs.description, getSetting(chatID, rs.name)), settingsCallbackPrefix+rs.name)))
+	}
+	return tg.NewInlineKeyboardMarkup(rows...)
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+)
+
+const settingsTableName = "settings"
+const setSettingSavePoint = "SetSetting"
+
+// Names of the house rules that each chat can configure.
+const (
+	settingMode        = "mode"
+	settingTurns       = "turns"
+	settingLostPts     = "lostpts"
+	settingAddPts      = "addpts"
+	settingYouon       = "youon"
+	settingDakuten     = "dakuten"
+	settingLongVowel   = "longvowel"
+	settingChainLength = "chainlen"
+	settingMinMorae    = "minmorae"
+	settingGenre       = "genre"
+	settingJLPT        = "jlpt"
+	settingGrade       = "grade"
+	settingHomophones  = "homophones"
+	settingTimeLimit   = "timelimit"
+	settingLives       = "lives"
+	settingWinPts      = "winpts"
+	settingInput       = "input"
+	settingNeedReply   = "needreply"
+	settingStatusMsg   = "statusmsg"
+	settingScoring     = "scoring"
+	settingBonuses     = "bonuses"
+	settingRomaji      = "romaji"
+)
+
+// Values of the game mode.
+const (
+	modeStandard = "standard"
+	modeKanji    = "kanji"
+	modeReverse  = "reverse"
+)
+
+// Values of the youon chaining rule.
+const (
+	youonCombo = "combo"
+	youonLarge = "large"
+)
+
+// Values of the romaji input setting.
+const (
+	romajiAuto = "auto"
+	romajiOn   = "on"
+	romajiOff  = "off"
+)
+
+// Setting value that turns off a setting that has no fixed choices.
+const settingOff = "off"
+
+// Values of the homophone rule.
+const (
+	homophonesAllow  = "allow"
+	homophonesReject = "reject"
+)
+
+// Values of the rule for which group chat messages are word submissions.
+const (
+	inputAll      = "all"
+	inputJapanese = "japanese"
+	inputReply    = "reply"
+	inputPrefix   = "prefix"
+)
+
+// The prefix of word submissions when the input rule is prefix (i.e., !すし).
+const inputPrefixes = "!！"
+
+// Values of the long vowel (ー) chaining rule.
+const (
+	longVowelVowel  = "vowel"
+	longVowelIgnore = "ignore"
+	longVowelEither = "either"
+)
+
+// A house rule that each chat can change with the /settings command.
+type ruleSetting struct {
+	name         string
+	description  string
+	defaultValue string
+	choices      []string
+}
+
+// The house rules, in the order they are displayed by /settings.
+var ruleSettings = []*ruleSetting{
+	// kanji: 学校 → 校長 → 長所, reverse: りす → とり → おと
+	{name: settingMode, description: "ゲームの種類", defaultValue: modeStandard, choices: []string{modeStandard, modeKanji, modeReverse}},
+	{name: settingTurns, description: "順番を守る", defaultValue: "on", choices: []string{"on", "off"}},
+	{name: settingLostPts, description: "負けた時の減点", defaultValue: "3", choices: []string{"0", "1", "2", "3", "5", "10"}},
+	{name: settingAddPts, description: "言葉を追加した時の得点", defaultValue: "1", choices: []string{"0", "1", "2", "3"}},
+	// combo: じてんしゃ → しゃこ, large: じてんしゃ → やま
+	{name: settingYouon, description: "拗音で終わる言葉の次", defaultValue: youonCombo, choices: []string{youonCombo, youonLarge}},
+	// on: スープ → ふろ, さと → どち
+	{name: settingDakuten, description: "濁点・半濁点を無視", defaultValue: "off", choices: []string{"on", "off"}},
+	// vowel: ミキサー → あき, ignore: ミキサー → さくら, either: both
+	{name: settingLongVowel, description: "長音(ー)で終わる言葉の次", defaultValue: longVowelVowel, choices: []string{longVowelVowel, longVowelIgnore, longVowelEither}},
+	// 2: みかん → かんじ
+	{name: settingChainLength, description: "つなげる拍数", defaultValue: "1", choices: []string{"1", "2"}},
+	// 3: き and いす are too short, but さくら is fine.
+	{name: settingMinMorae, description: "言葉の最低拍数", defaultValue: "1", choices: []string{"1", "2", "3", "4", "5"}},
+	// Only words with this JMdict field tag (food, MA, Buddh, ...) count. Changed with /genre.
+	{name: settingGenre, description: "ジャンル", defaultValue: settingOff},
+	// The old JLPT levels of kanjidic2, from 4 (easiest) to 1 (hardest).
+	{name: settingJLPT, description: "JLPTの級まで漢字", defaultValue: settingOff, choices: []string{settingOff, "4", "3", "2", "1"}},
+	// The kanjidic2 school grades: 1-6 are kyouiku kanji, 8 is the rest of the jouyou kanji, and 9 is jinmeiyou kanji.
+	{name: settingGrade, description: "学年まで漢字", defaultValue: settingOff, choices: []string{settingOff, "1", "2", "3", "4", "5", "6", "8", "9"}},
+	// reject: 巣 and 酢 are both read す, so only one of them can be played.
+	{name: settingHomophones, description: "同じ読みの言葉", defaultValue: homophonesAllow, choices: []string{homophonesAllow, homophonesReject}},
+	// Minutes to answer before losing the game.
+	{name: settingTimeLimit, description: "回答の制限時間(分)", defaultValue: settingOff, choices: []string{settingOff, "1", "5", "10", "30", "60", "720", "1440"}},
+	// Mistakes cost a life instead of ending the game, and the last player with lives left wins.
+	{name: settingLives, description: "ライフの数", defaultValue: settingOff, choices: []string{settingOff, "1", "2", "3", "5"}},
+	{name: settingWinPts, description: "勝者のボーナス", defaultValue: "5", choices: []string{"0", "3", "5", "10", "20"}},
+	// Other group chat messages are ignored. japanese: only kana and kanji, reply: only replies to the bot, prefix: only messages starting with '!'.
+	{name: settingInput, description: "言葉として扱うメッセージ", defaultValue: inputAll, choices: []string{inputAll, inputJapanese, inputReply, inputPrefix}},
+	// off: a word that isn't a reply answers the current word, unless a newer word was played after it was sent.
+	{name: settingNeedReply, description: "現在の言葉への返信が必要", defaultValue: "on", choices: []string{"on", "off"}},
+	// on: one pinned message shows the state of the game, and is edited after each word instead of sending a new message.
+	{name: settingStatusMsg, description: "ピン留めの状況メッセージ", defaultValue: "off", choices: []string{"on", "off"}},
+	// kanji: the hardest kanji, morae: the length of the reading, rarity: rare words, flat: every word is worth 1 point.
+	{name: settingScoring, description: "採点方法", defaultValue: scoringKanji, choices: getScoringPolicyNames()},
+	// Extra points for long chains, for a player's consecutive words, and for quick answers.
+	{name: settingBonuses, description: "連鎖・連続・速さのボーナス", defaultValue: "off", choices: []string{"on", "off"}},
+	// auto: romaji is played in private chats, and in groups whose input is a reply or a prefix, where chatter isn't mistaken for a word.
+	{name: settingRomaji, description: "ローマ字の入力", defaultValue: romajiAuto, choices: []string{romajiAuto, romajiOn, romajiOff}},
+}
+
+func findRuleSetting(name string) *ruleSetting {
+	for _, rs := range ruleSettings {
+		if rs.name == name {
+			return rs
+		}
+	}
+	return nil
+}
+
+func (rs *ruleSetting) validChoice(value string) bool {
+	for _, choice := range rs.choices {
+		if choice == value {
+			return true
+		}
+	}
+	return false
+}
+
+func (rs *ruleSetting) nextChoice(value string) string {
+	for index, choice := range rs.choices {
+		if choice == value {
+			return rs.choices[(index+1)%len(rs.choices)]
+		}
+	}
+	return rs.defaultValue
+}
+
+func getSetting(chatID int64, name string) string {
+	var value string
+	if nil != gamedb.SingleQuery(fmt.Sprintf("SELECT value FROM %s WHERE chatid = %d AND name = '%s'", settingsTableName, chatID, name), &value) {
+		if rs := findRuleSetting(name); rs != nil {
+			return rs.defaultValue
+		}
+	}
+	return value
+}
+
+func getIntSetting(chatID int64, name string) int {
+	value, _ := strconv.Atoi(getSetting(chatID, name))
+	return value
+}
+
+func getBoolSetting(chatID int64, name string) bool {
+	return getSetting(chatID, name) == "on"
+}
+
+func setSetting(chatID int64, name string, value string) error {
+	// Replace any existing value with the new one.
+	return gamedb.ExecWithSavePoint(setSettingSavePoint, func() error {
+		if err := gamedb.Exec(fmt.Sprintf("DELETE FROM %s WHERE chatid = ? AND name = ?", settingsTableName), chatID, name); err != nil {
+			return err
+		}
+		return gamedb.Exec(fmt.Sprintf("INSERT INTO %s (chatid, name, value) VALUES (?, ?, ?)", settingsTableName), chatID, name, value)
+	})
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	tg "github.com/semog/go-bot-api/v5"
+)
+
+// The number of players shown in the top scores of the status message.
+const statusTopScores = 3
+
+// How often to check that the status message is still pinned.
+const statusPinCheckInterval = 10 * time.Minute
+
+// The last time that the pin of each chat's status message was checked. The game lock guards it.
+var statusPinChecks = make(map[int64]time.Time)
+
+// Edit the chat's pinned status message to show the state of the game. If it was deleted, a new one is sent and pinned.
+// Returns false if the chat doesn't use a status message, so the caller should send the current word instead.
+func updateStatusMsg(bot *tg.BotAPI, chatID int64) bool {
+	messageID := getStatusMsgID(chatID)
+	if !getBoolSetting(chatID, settingStatusMsg) {
+		if messageID != 0 {
+			// The status message was turned off, so take it down.
+			bot.Request(tg.UnpinChatMessageConfig{ChatID: chatID, MessageID: messageID})
+			clearStatusMsgID(chatID)
+		}
+		return false
+	}
+	status := getStatusDisplay(chatID)
+	if messageID != 0 {
+		if _, err := bot.Send(tg.NewEditMessageText(chatID, messageID, status)); err != nil && !strings.Contains(err.Error(), "not modified") {
+			log.Printf("Could not edit the status message of chat [%d]: %v", chatID, err)
+			messageID = 0
+		}
+	}
+	if messageID == 0 {
+		sent, err := bot.Send(tg.NewMessage(chatID, status))
+		if err != nil {
+			log.Printf("Could not send the status message of chat [%d]: %v", chatID, err)
+			return false
+		}
+		messageID = sent.MessageID
+		saveStatusMsgID(chatID, messageID)
+		pinStatusMsg(bot, chatID, messageID)
+	} else if time.Since(statusPinChecks[chatID]) >= statusPinCheckInterval {
+		checkStatusMsgPin(bot, chatID, messageID)
+	}
+	// Players can reply to the status message with the next word. It is not saved as a current word message,
+	// because it is edited, so the word that a reply answers is checked from its text.
+	return true
+}
+
+// Pin the status message again if somebody unpinned it.
+func checkStatusMsgPin(bot *tg.BotAPI, chatID int64, messageID int) {
+	statusPinChecks[chatID] = time.Now()
+	chat, err := bot.GetChat(tg.ChatInfoConfig{ChatConfig: tg.ChatConfig{ChatID: chatID}})
+	if err == nil && chat.PinnedMessage != nil && chat.PinnedMessage.MessageID == messageID {
+		return
+	}
+	pinStatusMsg(bot, chatID, messageID)
+}
+
+func pinStatusMsg(bot *tg.BotAPI, chatID int64, messageID int) {
+	statusPinChecks[chatID] = time.Now()
+	if _, err := bot.Request(tg.PinChatMessageConfig{ChatID: chatID, MessageID: messageID, DisableNotification: true}); err != nil {
+		log.Printf("Could not pin the status message of chat [%d]: %v", chatID, err)
+	}
+}
+
+func getStatusDisplay(chatID int64) string {
+	chat := &tg.Chat{ID: chatID}
+	status := fmt.Sprintf("📌しりとりの状況\n＿＿＿＿＿＿＿＿＿＿＿\n%s", getCurrentWordEntryDisplay(chat, true))
+	if entry := getLastEntry(chatID); entry != nil {
+		rules := getChainRules(chatID)
+		switch {
+		case getSetting(chatID, settingMode) == modeKanji:
+			status += fmt.Sprintf("\n次の言葉は「%s」で始まる必要があります。", getLastKanji(entry.word))
+		case !rules.reverse:
+			// The current word display already shows the expected kana in reverse mode.
+			status += "\n" + getExpectedKanaDisplay(getChainKana(entry.word, getEntryKana(chatID, entry), rules), rules)
+		}
+		status += fmt.Sprintf("\nつながった言葉の数: %d", len(getWordHistory(chatID)))
+	}
+	status += "\n＿＿＿＿＿＿＿＿＿＿＿"
+	for index, player := range getPlayers(chatID) {
+		if index >= statusTopScores {
+			break
+		}
+		status += fmt.Sprintf("\n%d. %s 【%d得点】", index+1, formatPlayerName(player), player.score)
+	}
+	return status
+}
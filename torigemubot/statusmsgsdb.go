@@ -0,0 +1,21 @@
+package main
+
+import (
+	"fmt"
+)
+
+const statusmsgsTableName = "statusmsgs"
+
+func getStatusMsgID(chatID int64) int {
+	var messageID int
+	gamedb.SingleQuery(fmt.Sprintf("SELECT messageid FROM %s WHERE chatid = %d", statusmsgsTableName, chatID), &messageID)
+	return messageID
+}
+
+func saveStatusMsgID(chatID int64, messageID int) error {
+	return gamedb.Exec(fmt.Sprintf("INSERT OR REPLACE INTO %s (chatid, messageid) VALUES (?, ?)", statusmsgsTableName), chatID, messageID)
+}
+
+func clearStatusMsgID(chatID int64) error {
+	return gamedb.Exec(fmt.Sprintf("DELETE FROM %s WHERE chatid = %d", statusmsgsTableName, chatID))
+}
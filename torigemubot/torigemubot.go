@@ -5,6 +5,8 @@ import (
 	"log"
 	"regexp"
 	"strings"
+	"sync"
+	"unicode"
 
 	tg "github.com/semog/go-bot-api/v5"
 	"k8s.io/klog"
@@ -16,23 +18,32 @@ Use the /setcommands command and reply with the following list of commands.
 ---------------------
 current - Show the current word.
 history - Show the words that have been used in the game.
+games - Show the games that were played before.
+game - Show the words of a game that was played before.
 scores - Show the current scores.
+scorelog - Show why the scores changed.
 nick - Set your nickname.
 add - Add a custom word to this group's game.
 remove - Remove a custom word from this group's game.
+settings - Show or change this group's house rules.
+genre - Show the genres, or limit the game to one genre.
+join - Join the play order of this group's game.
+leave - Leave the play order of this group's game.
+start - Start a game where the players take turns in order.
+skip - Skip the turn of a player that has not answered for a while.
 help - Display game rules and other instructions.
 */
 
 var torigemubot = tg.BotEventHandlers{
-	OnInitialize: torigemubotOnInitialize,
-	OnDispose:    torigemubotOnDispose,
-	OnCommand:    torigemubotOnCommand,
+	OnInitialize:    torigemubotOnInitialize,
+	OnDispose:       torigemubotOnDispose,
+	OnCommand:       torigemubotOnCommand,
+	OnMessage:       torigemubotOnMessage,
+	OnCallbackQuery: torigemubotOnCallbackQuery,
 }
 
-const lostGamePts = 3
-const addWordPts = 1
-
 const newGamePrompt = "始める新しい単語を入力して下さい。"
+const currentWordMarker = "》"
 
 // TODO: Add cleanup of game data from the database if a chat is destroyed, or the bot is kicked out (same thing).
 
@@ -46,55 +57,95 @@ type playerEntry struct {
 	nickname  string
 	score     int
 	numWords  int
+	wins      int
 }
 type playerList []*playerEntry
 
 // Track the words used for each game.
 type wordEntry struct {
-	chatid int64
-	userid int64
-	word   string
-	points int
+	chatid  int64
+	userid  int64
+	word    string
+	reading string
+	matched string
+	chosen  string
+	points  int
+	// The scoring policy that scored the word, and how it scored it.
+	scoring   string
+	breakdown string
+	// The date of the message that played the word.
+	sentAt int64
+	// The bonus points are recorded separately from the points of the word.
+	chainBonus  int
+	streakBonus int
+	speedBonus  int
 }
 type wordList []*wordEntry
 
 var kanjiExp = regexp.MustCompile(`(\p{Han}|\p{Katakana}|\p{Hiragana}|ー)+`)
+var chosenReadingExp = regexp.MustCompile(`^(.+?)[（(]([\p{Hiragana}\p{Katakana}ー]+)[）)]$`)
 var addCustomWordExp = regexp.MustCompile(`(?i)([\p{Han}|\p{Katakana}|\p{Hiragana}|ー]+)[ 　　\t]+([\p{Hiragana}|,|、]+)`)
 var removeCustomWordExp = regexp.MustCompile(`(?i)([\p{Han}|\p{Katakana}|\p{Hiragana}|ー]+)`)
 
+// The bot events and the turn timer take turns updating the games.
+var gameLock sync.Mutex
+var stopTurnTimer = make(chan bool)
+
 // Initialize global data
 func torigemubotOnInitialize(bot *tg.BotAPI) bool {
 	if err := initgameDb(); err != nil {
 		klog.Errorf("could not initialize database: %v\n", err)
 		return false
 	}
+	go runTurnTimer(bot, stopTurnTimer)
 	return true
 }
 
 func torigemubotOnDispose(bot *tg.BotAPI) {
 	// Do any cleanup of external resources.
+	close(stopTurnTimer)
 }
 
 func torigemubotOnCommand(bot *tg.BotAPI, cmd string, msg *tg.Message) bool {
+	gameLock.Lock()
+	defer gameLock.Unlock()
 	log.Printf("Command From: Chat %s, User %s %s (%s): %s - %s",
 		formatChatName(msg.Chat), msg.From.FirstName, msg.From.LastName, msg.From.UserName, cmd, msg.Text)
 	switch strings.ToLower(cmd) {
 	case "":
-		if len(msg.Text) > 0 {
-			doWordEntry(bot, msg)
+		if submission, ok := getSubmission(bot, msg); ok {
+			doWordEntry(bot, msg, submission)
 		}
 	case "current":
 		doShowCurrentWord(bot, msg, true)
 	case "history":
 		doShowHistory(bot, msg.Chat.ID)
+	case "games":
+		doShowGames(bot, msg)
+	case "game":
+		doShowGame(bot, msg)
 	case "scores":
 		doShowScores(bot, msg.Chat.ID)
+	case "scorelog":
+		doScoreLog(bot, msg)
 	case "nick":
 		doSetNickname(bot, msg)
 	case "add":
 		doAddWord(bot, msg)
 	case "remove":
 		doRemoveWord(bot, msg)
+	case "settings":
+		doSettings(bot, msg)
+	case "genre":
+		doGenre(bot, msg)
+	case "join":
+		doJoin(bot, msg)
+	case "leave":
+		doLeave(bot, msg)
+	case "start":
+		doStart(bot, msg)
+	case "skip":
+		doSkip(bot, msg)
 	case "help":
 		doHelp(bot, msg)
 	case "shutdown":
@@ -103,15 +154,42 @@ func torigemubotOnCommand(bot *tg.BotAPI, cmd string, msg *tg.Message) bool {
 	return true
 }
 
+// Messages that aren't commands may be word submissions.
+func torigemubotOnMessage(bot *tg.BotAPI, msg *tg.Message) bool {
+	return torigemubotOnCommand(bot, "", msg)
+}
+
+func torigemubotOnCallbackQuery(bot *tg.BotAPI, query *tg.CallbackQuery) bool {
+	gameLock.Lock()
+	defer gameLock.Unlock()
+	log.Printf("Callback From: User %s %s (%s): %s",
+		query.From.FirstName, query.From.LastName, query.From.UserName, query.Data)
+	switch {
+	case strings.HasPrefix(query.Data, settingsCallbackPrefix):
+		doSettingsCallback(bot, query)
+	}
+	return true
+}
+
 func doShowCurrentWord(bot *tg.BotAPI, msg *tg.Message, showUserInfo bool) {
-	bot.Send(tg.NewMessage(msg.Chat.ID, getCurrentWordEntryDisplay(msg.Chat, showUserInfo)))
+	sent, err := bot.Send(tg.NewMessage(msg.Chat.ID, getCurrentWordEntryDisplay(msg.Chat, showUserInfo)))
+	if lastentry := getLastEntry(msg.Chat.ID); err == nil && lastentry != nil {
+		saveCurrentWordMsg(msg.Chat.ID, sent.MessageID, lastentry.word)
+	}
 }
 
 func doShowScores(bot *tg.BotAPI, chatID int64) {
 	log.Println("Received showscores command.")
-	scores := "*ゲームの得点は*\n＿＿＿＿＿＿＿＿＿＿＿"
+	scores := fmt.Sprintf("*ゲームの得点は*\n採点方法: %s\n＿＿＿＿＿＿＿＿＿＿＿", findScoringPolicy(getSetting(chatID, settingScoring)).description)
+	components := getScoreComponents(chatID)
 	for _, player := range getPlayers(chatID) {
 		scores += fmt.Sprintf("\n%s 【%d得点】「%d言葉」", formatPlayerName(player), player.score, player.numWords)
+		if player.wins > 0 {
+			scores += fmt.Sprintf("🏆%d", player.wins)
+		}
+		if display := getScoreComponentsDisplay(components[player.userid]); len(display) > 0 {
+			scores += "\n　" + display
+		}
 	}
 	msg := tg.NewMessage(chatID, scores)
 	msg.ParseMode = tg.ModeMarkdown
@@ -129,26 +207,75 @@ func doShowHistory(bot *tg.BotAPI, chatID int64) {
 	bot.Send(msg)
 }
 
-func doWordEntry(bot *tg.BotAPI, msg *tg.Message) {
+func doWordEntry(bot *tg.BotAPI, msg *tg.Message, submission string) {
 	log.Println("Received a word submission.")
-	theWord := msg.Text
+	theWord := normalizeWord(submission)
+	chosen := ""
+	// A reading can be chosen for words with more than one (i.e., 上手（うわて）).
+	if chosenReading := chosenReadingExp.FindStringSubmatch(theWord); len(chosenReading) == 3 {
+		theWord = chosenReading[1]
+		chosen = toHiragana(chosenReading[2])
+	}
 	chatID := msg.Chat.ID
+	romaji := romajiAllowed(msg.Chat)
+	if !isWordInput(theWord, romaji) {
+		if isRomaji(theWord) {
+			// Chatter in letters is ignored when the chat doesn't play in romaji.
+			return
+		}
+		// Nobody loses points for something that isn't even a word.
+		if romaji {
+			sendReplyMsg(bot, msg, "(・・?)\n言葉ではないようです。漢字、仮名、またはローマ字で入力して下さい。")
+		} else {
+			sendReplyMsg(bot, msg, "(・・?)\n言葉ではないようです。漢字または仮名で入力して下さい。")
+		}
+		return
+	}
 	player := getPlayer(chatID, msg.From)
 	lastentry := getLastEntry(msg.Chat.ID)
+	turn := getLobbyTurn(chatID)
+	if turn != nil && turn.userid != player.userid {
+		turnPlayer, _ := getPlayerByID(chatID, turn.userid)
+		sendReplyMsg(bot, msg, fmt.Sprintf("%s様お待ち下さい。今は%s様の番です。\nヽ(^o^)丿", formatPlayerName(player), formatPlayerName(turnPlayer)))
+		return
+	}
+	if playerIsOut(chatID, player.userid) {
+		sendReplyMsg(bot, msg, fmt.Sprintf("%s様は脱落しました。次のゲームを待って下さい。", formatPlayerName(player)))
+		return
+	}
 	// Private chats don't have to take turns.
 	if lastentry != nil && !msg.Chat.IsPrivate() {
-		if userSubmittedLastWord(msg, lastentry) {
+		// The play order already decides whose turn it is, even when a lost life gives the same word back to its player.
+		if turn == nil && getBoolSetting(chatID, settingTurns) && userSubmittedLastWord(msg, lastentry) {
 			bot.Send(tg.NewMessage(chatID, fmt.Sprintf("%s様お待ち下さい。他の人が最初に行くようにしましょう。\nヽ(^o^)丿", formatPlayerName(player))))
 			doShowCurrentWord(bot, msg, false)
 			return
 		}
-		if !respondingToCurrentWord(msg, lastentry) {
-			sendReplyMsg(bot, msg, fmt.Sprintf("ヽ(^o^)丿\n%s様は遅いです。\n現在の言葉は：", formatPlayerName(player)))
+		if !respondingToCurrentWord(bot, msg, lastentry) {
+			late := fmt.Sprintf("ヽ(^o^)丿\n%s様は遅いです。", formatPlayerName(player))
+			if repliedWord := getRepliedWord(bot, msg); len(repliedWord) > 0 {
+				// Show the newer word that the reply was racing against.
+				late += fmt.Sprintf("「%s」の後に、もう「%s」が出ました。", repliedWord, lastentry.word)
+			} else if getBoolSetting(chatID, settingNeedReply) {
+				late = fmt.Sprintf("ヽ(^o^)丿\n%s様、現在の言葉に返信して下さい。", formatPlayerName(player))
+			}
+			sendReplyMsg(bot, msg, late+"\n現在の言葉は：")
 			doShowCurrentWord(bot, msg, true)
 			return
 		}
 	}
 
+	// Players without a Japanese keyboard can type the word in romaji (i.e., sushi → すし).
+	if romaji && isRomaji(theWord) {
+		kana, ok := romajiToKana(theWord)
+		if !ok {
+			sendReplyMsg(bot, msg, fmt.Sprintf("❌ローマ字を仮名に変換できませんでした: %s「%s」", theWord, kana))
+			return
+		}
+		sendReplyMsg(bot, msg, fmt.Sprintf("ローマ字: %s → %s", theWord, kana))
+		theWord = kana
+	}
+
 	// Even if the second word is invalid, the first word points need to be applied.
 	gamedb.BeginTrans()
 	defer gamedb.CommitTrans()
@@ -158,36 +285,66 @@ func doWordEntry(bot *tg.BotAPI, msg *tg.Message) {
 	if firstEntry != nil {
 		firstword = false
 		if firstEntry.points == 0 {
-			firstWordPts, _ := getWordPts(chatID, firstEntry.word, nil)
+			firstWordPts, _, breakdown, _ := getModeWordPts(chatID, firstEntry.word, firstEntry.chosen, nil)
 			// Now award the points to the player who went first.
-			updateFirstEntryPoints(chatID, firstWordPts)
-			updatePlayerScore(chatID, firstEntry.userid, firstWordPts)
+			updateFirstEntryPoints(chatID, firstWordPts, findScoringPolicy(getSetting(chatID, settingScoring)).name, breakdown)
+			updatePlayerScore(chatID, firstEntry.userid, firstWordPts, scoreReasonFirstWord, firstEntry.word)
 		}
 	}
 	if alreadyUsedWord(chatID, theWord) {
-		userLostGame(bot, player, fmt.Sprintf("すでに使用されている言葉: %s", theWord))
-		newGame(bot, msg.Chat)
+		playerMadeMistake(bot, player, fmt.Sprintf("すでに使用されている言葉: %s", theWord))
 		return
 	}
 	// Checking word validity is a longer operation, so we do it last.
-	entryPts, ptsMsg := getWordPts(chatID, theWord, lastentry)
+	entryPts, reading, breakdown, ptsMsg := getModeWordPts(chatID, theWord, chosen, lastentry)
 	if entryPts == 0 {
-		userLostGame(bot, player, ptsMsg)
-		newGame(bot, msg.Chat)
+		playerMadeMistake(bot, player, ptsMsg)
 		return
 	}
+	if getSetting(chatID, settingHomophones) == homophonesReject {
+		unused, earlierEntry := removeUsedReadings(chatID, reading)
+		if len(unused) == 0 {
+			playerMadeMistake(bot, player, fmt.Sprintf("同じ読みの言葉はすでに使用されています: %s「%s」← %s「%s」", theWord, reading, earlierEntry.word, earlierEntry.reading))
+			return
+		}
+		reading = unused
+	}
 
+	entry := &wordEntry{
+		chatid:    chatID,
+		word:      theWord,
+		reading:   reading,
+		matched:   getMatchedWords(chatID, theWord),
+		chosen:    chosen,
+		userid:    player.userid,
+		points:    entryPts,
+		scoring:   findScoringPolicy(getSetting(chatID, settingScoring)).name,
+		breakdown: breakdown,
+		sentAt:    int64(msg.Date)}
+	updatePlayerStreak(chatID, player.userid, getPlayerStreak(chatID, player.userid)+1)
 	if !firstword {
-		updatePlayerScore(chatID, player.userid, entryPts)
+		if getBoolSetting(chatID, settingBonuses) {
+			addWordBonuses(entry, lastentry)
+		}
+		updatePlayerScore(chatID, player.userid, entry.points, scoreReasonWord, theWord)
+		if bonusPts := entry.getBonusPts(); bonusPts > 0 {
+			updatePlayerScore(chatID, player.userid, bonusPts, scoreReasonBonus, theWord)
+		}
 	} else {
-		entryPts = 0
-	}
-	addEntry(&wordEntry{
-		chatid: chatID,
-		word:   theWord,
-		userid: player.userid,
-		points: entryPts})
-	doShowCurrentWord(bot, msg, false)
+		entry.points = 0
+	}
+	if err := addEntry(entry); err != nil {
+		klog.Error(err)
+		sendReplyMsg(bot, msg, "❌誤りです。言葉を追加できませんでした。")
+		return
+	}
+	updateMissedTurns(chatID, player.userid, 0)
+	advanceLobbyTurn(chatID)
+	startTurnTimer(chatID, getNextPlayerID(msg.Chat))
+	if !updateStatusMsg(bot, chatID) {
+		doShowCurrentWord(bot, msg, false)
+	}
+	announceLobbyTurn(bot, chatID)
 }
 
 func doSetNickname(bot *tg.BotAPI, msg *tg.Message) {
@@ -270,10 +427,18 @@ func doHelp(bot *tg.BotAPI, msg *tg.Message) {
 ⑦ If the word is katakana and ends in ー, then start with the vowel sound that it is extending. For example:
       マスター, next word should start with あ.
       ルビー, next word should start with い.
+⑧ To chain from only one reading of a word that has more than one, add the reading after the word, such as 「上手（うわて）」.
+⑨ Words may also be typed in romaji, such as "sushi" for 「すし」. In groups, use /settings romaji to allow it.
 
 Example: sakura 「さくら」 → rajio 「ラジオ」 → onigiri 「おにぎり」 → risu 「りす」 → sumou 「すもう」 → udon 「うどん」
 
-The player who used the word udon lost this game.`))
+The player who used the word udon lost this game.
+
+Use /settings lives to play until only one player is left standing.
+Use /join and /start to take turns in a fixed order. If a player doesn't answer for a while, /skip their turn.
+Use /scorelog to see why the scores changed.
+Use /games and /game to look back at the games that were played before.
+Use /settings to see or change this group's house rules.`))
 }
 
 func doShutdown(bot *tg.BotAPI, msg *tg.Message) bool {
@@ -291,9 +456,18 @@ func sendReplyMsg(bot *tg.BotAPI, msg *tg.Message, message string) {
 	bot.Send(reply)
 }
 
-func newGame(bot *tg.BotAPI, chat *tg.Chat) {
-	clearWordHistory(chat.ID)
-	bot.Send(tg.NewMessage(chat.ID, fmt.Sprintf("新しいゲームを開始します。\n%s\n(^_^)/", newGamePrompt)))
+func newGame(bot *tg.BotAPI, chatID int64) {
+	// A game that nobody lost was stopped, such as when the rules changed.
+	endGame(chatID, 0, "")
+	clearTurnTimer(chatID)
+	clearLives(chatID)
+	clearPlayerStreaks(chatID)
+	bot.Send(tg.NewMessage(chatID, fmt.Sprintf("新しいゲームを開始します。\n%s\n(^_^)/", newGamePrompt)))
+	updateStatusMsg(bot, chatID)
+	if turn := getLobbyTurn(chatID); turn != nil {
+		startTurnTimer(chatID, turn.userid)
+		announceLobbyTurn(bot, chatID)
+	}
 }
 
 func getCurrentWordEntryDisplay(chat *tg.Chat, showUserInfo bool) string {
@@ -302,7 +476,12 @@ func getCurrentWordEntryDisplay(chat *tg.Chat, showUserInfo bool) string {
 	if entry == nil {
 		entryDisplay = newGamePrompt
 	} else {
-		entryDisplay = fmt.Sprintf("》%s", getWordEntryDisplay(chat.ID, entry, showUserInfo))
+		entryDisplay = currentWordMarker + getWordEntryDisplay(chat.ID, entry, showUserInfo)
+		if rules := getChainRules(chat.ID); rules.reverse {
+			// The kana that the next word must end with is not as obvious as the one it must begin with.
+			kana := getEntryKana(chat.ID, entry)
+			entryDisplay += "\n" + getExpectedKanaDisplay(kana, rules)
+		}
 	}
 	return entryDisplay
 }
@@ -311,24 +490,100 @@ func getWordEntryDisplay(chatID int64, entry *wordEntry, showUserInfo bool) stri
 	playername := ""
 	bonus := ""
 	pts := entry.points
+	breakdown := entry.breakdown
 	if pts == 0 {
 		// The points haven't been awarded yet, so we calc them and flag the entry.
-		pts = calcWordPoints(entry.word)
+		pts, _, breakdown, _ = getModeWordPts(chatID, entry.word, entry.chosen, nil)
 		bonus += "★"
 	}
 	if showUserInfo {
 		player, _ := getPlayerByID(chatID, entry.userid)
 		playername = fmt.Sprintf("「%s」", formatPlayerName(player))
 	}
-	return fmt.Sprintf("%s【%d得点】%s%s", entry.word, pts, bonus, playername)
+	word := entry.word
+	if len(entry.chosen) > 0 {
+		word += fmt.Sprintf("（%s）", entry.chosen)
+	} else if len(entry.matched) > 0 {
+		// Show the words that the kana could be.
+		word += fmt.Sprintf("（%s）", strings.Replace(entry.matched, ",", "・", -1))
+	}
+	bonus += entry.getBonusDisplay()
+	if len(breakdown) > 0 {
+		// Show how the scoring policy of the time scored the word.
+		return fmt.Sprintf("%s【%d得点: %s】%s%s", word, pts, breakdown, bonus, playername)
+	}
+	return fmt.Sprintf("%s【%d得点】%s%s", word, pts, bonus, playername)
+}
+
+// Get the player whose turn is next, or zero if any other player may go next.
+func getNextPlayerID(chat *tg.Chat) int64 {
+	if chat.IsPrivate() {
+		// The private chat ID is the player's user ID.
+		return chat.ID
+	}
+	if turn := getLobbyTurn(chat.ID); turn != nil {
+		return turn.userid
+	}
+	return 0
+}
+
+// Get the word submission of the message, following the chat's input rule. Group chat messages that aren't submissions are silently ignored.
+func getSubmission(bot *tg.BotAPI, msg *tg.Message) (string, bool) {
+	if len(msg.Text) == 0 {
+		return "", false
+	}
+	if msg.Chat.IsPrivate() {
+		return msg.Text, true
+	}
+	switch getSetting(msg.Chat.ID, settingInput) {
+	case inputJapanese:
+		// A sentence with spaces (i.e., すし が すき) is chatter, even though it is all kana and kanji.
+		if strings.IndexFunc(strings.TrimSpace(msg.Text), unicode.IsSpace) >= 0 {
+			return "", false
+		}
+		word := normalizeWord(msg.Text)
+		if chosenReading := chosenReadingExp.FindStringSubmatch(word); len(chosenReading) == 3 {
+			word = chosenReading[1]
+		}
+		return msg.Text, len(word) > 0 && kanjiExp.FindString(word) == word
+	case inputReply:
+		return msg.Text, msg.ReplyToMessage != nil && msg.ReplyToMessage.From != nil && msg.ReplyToMessage.From.ID == bot.Self.ID
+	case inputPrefix:
+		text := strings.TrimSpace(msg.Text)
+		for _, prefix := range inputPrefixes {
+			if strings.HasPrefix(text, string(prefix)) {
+				return strings.TrimPrefix(text, string(prefix)), true
+			}
+		}
+		return "", false
+	}
+	return msg.Text, true
+}
+
+// Romaji is only played where ordinary chatter in letters can't be mistaken for a word, unless the chat turns it on.
+func romajiAllowed(chat *tg.Chat) bool {
+	switch getSetting(chat.ID, settingRomaji) {
+	case romajiOn:
+		return true
+	case romajiOff:
+		return false
+	}
+	if chat.IsPrivate() {
+		return true
+	}
+	input := getSetting(chat.ID, settingInput)
+	return input == inputReply || input == inputPrefix
 }
 
 func userSubmittedLastWord(msg *tg.Message, lastentry *wordEntry) bool {
-	return !*noturns && lastentry.userid == msg.From.ID
+	return lastentry.userid == msg.From.ID
 }
 
 func userLostGame(bot *tg.BotAPI, player *playerEntry, reason string) {
-	updatePlayerScore(player.chatid, player.userid, -lostGamePts)
+	updatePlayerScore(player.chatid, player.userid, -getIntSetting(player.chatid, settingLostPts), scoreReasonLost, "")
+	endGame(player.chatid, player.userid, reason)
+	// The next player in the play order starts the new game.
+	advanceLobbyTurn(player.chatid)
 	bot.Send(tg.NewMessage(player.chatid, fmt.Sprintf("❌%s様はゲームを負けました！\n%s\n＿|￣|○", formatPlayerName(player), reason)))
 }
 
@@ -361,10 +616,42 @@ func formatPlayerName(player *playerEntry) string {
 	return player.nickname
 }
 
-func respondingToCurrentWord(msg *tg.Message, lastentry *wordEntry) bool {
-	if msg.ReplyToMessage == nil {
+func respondingToCurrentWord(bot *tg.BotAPI, msg *tg.Message, lastentry *wordEntry) bool {
+	if repliedWord := getRepliedWord(bot, msg); len(repliedWord) > 0 {
+		return lastentry.word == repliedWord
+	}
+	if getBoolSetting(msg.Chat.ID, settingNeedReply) {
 		// Must have a reply to message.
 		return false
 	}
-	return lastentry.word == kanjiExp.FindString(msg.ReplyToMessage.Text)
+	// The word answers the current word, unless a newer word was played after the message was sent.
+	// Both are the dates of the messages, since the bot may handle a message some time after it was sent.
+	return int64(msg.Date) >= lastentry.sentAt
+}
+
+// Get the word that the message replies to, or "" if it doesn't reply to a word.
+// The bot's latest current word message, the bot's messages that show a word, and players' messages of a played word are replies to a word.
+func getRepliedWord(bot *tg.BotAPI, msg *tg.Message) string {
+	reply := msg.ReplyToMessage
+	if reply == nil {
+		return ""
+	}
+	if messageID, word := getCurrentWordMsg(msg.Chat.ID); reply.MessageID == messageID {
+		return word
+	}
+	if reply.From != nil && reply.From.ID == bot.Self.ID {
+		// The bot's messages show the word after 》, such as the current word and the status message.
+		if index := strings.Index(reply.Text, currentWordMarker); index >= 0 {
+			return kanjiExp.FindString(reply.Text[index:])
+		}
+		return ""
+	}
+	word := normalizeWord(reply.Text)
+	if chosenReading := chosenReadingExp.FindStringSubmatch(word); len(chosenReading) == 3 {
+		word = chosenReading[1]
+	}
+	if len(word) > 0 && alreadyUsedWord(msg.Chat.ID, word) {
+		return word
+	}
+	return ""
 }
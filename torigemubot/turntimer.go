@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	tg "github.com/semog/go-bot-api/v5"
+)
+
+// How often the turn timer checks the deadlines.
+const turnTimerInterval = 10 * time.Second
+
+// A warning is sent when only 1/2, and then 1/10, of the time limit remains.
+var turnWarningDivisors = []int64{2, 10}
+
+// The deadlines are kept in the database, so the timer picks up where it left off after a restart.
+func runTurnTimer(bot *tg.BotAPI, stop chan bool) {
+	ticker := time.NewTicker(turnTimerInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			gameLock.Lock()
+			checkTurnTimers(bot, now.Unix())
+			gameLock.Unlock()
+		}
+	}
+}
+
+func checkTurnTimers(bot *tg.BotAPI, now int64) {
+	for _, timer := range getTurnTimers() {
+		limit := int64(getIntSetting(timer.chatid, settingTimeLimit)) * 60
+		if limit == 0 {
+			// The time limit was turned off after the timer started.
+			clearTurnTimer(timer.chatid)
+			continue
+		}
+		remaining := timer.deadline - now
+		if remaining <= 0 {
+			turnTimedOut(bot, timer)
+			continue
+		}
+		warnings := 0
+		for _, divisor := range turnWarningDivisors {
+			if remaining <= limit/divisor {
+				warnings++
+			}
+		}
+		if warnings > timer.warnings {
+			updateTurnTimerWarnings(timer.chatid, warnings)
+			sendTurnWarning(bot, timer, remaining)
+		}
+	}
+}
+
+func sendTurnWarning(bot *tg.BotAPI, timer *turnTimer, remaining int64) {
+	warning := fmt.Sprintf("⏰残り時間は%sです。", formatDuration(remaining))
+	if player, err := getPlayerByID(timer.chatid, timer.userid); timer.userid != 0 && err == nil {
+		warning = fmt.Sprintf("⏰%s様、残り時間は%sです。", formatPlayerName(player), formatDuration(remaining))
+	}
+	chat := &tg.Chat{ID: timer.chatid}
+	bot.Send(tg.NewMessage(timer.chatid, fmt.Sprintf("%s\n現在の言葉は：\n%s", warning, getCurrentWordEntryDisplay(chat, true))))
+}
+
+func turnTimedOut(bot *tg.BotAPI, timer *turnTimer) {
+	log.Printf("Turn timed out in chat [%d].", timer.chatid)
+	clearTurnTimer(timer.chatid)
+	if player, err := getPlayerByID(timer.chatid, timer.userid); timer.userid != 0 && err == nil {
+		// The turn passes on before the player is skipped, so the next player doesn't get skipped with them.
+		playerMadeMistake(bot, player, "⏰時間切れです。")
+		lobbyPlayerMissedTurn(bot, timer.chatid, timer.userid)
+	} else {
+		// Anyone could have gone next, so nobody loses any points.
+		bot.Send(tg.NewMessage(timer.chatid, "⏰時間切れです。誰も答えませんでした。"))
+		endGame(timer.chatid, 0, "⏰時間切れです。誰も答えませんでした。")
+		newGame(bot, timer.chatid)
+	}
+}
+
+func formatDuration(seconds int64) string {
+	if seconds < 60 {
+		return fmt.Sprintf("%d秒", seconds)
+	}
+	if seconds < 3600 {
+		return fmt.Sprintf("%d分", (seconds+59)/60)
+	}
+	return fmt.Sprintf("%d時間%d分", seconds/3600, (seconds%3600+59)/60)
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+const turntimersTableName = "turntimers"
+const startTurnTimerSavePoint = "StartTurnTimer"
+
+// The deadline for answering the current word of a game.
+type turnTimer struct {
+	chatid   int64
+	userid   int64
+	deadline int64
+	warnings int
+}
+
+func startTurnTimer(chatID int64, userID int64) error {
+	limit := getIntSetting(chatID, settingTimeLimit)
+	if limit == 0 {
+		return clearTurnTimer(chatID)
+	}
+	// Replace the timer of the last word with the timer of the new word.
+	return gamedb.ExecWithSavePoint(startTurnTimerSavePoint, func() error {
+		if err := clearTurnTimer(chatID); err != nil {
+			return err
+		}
+		return gamedb.Exec(fmt.Sprintf("INSERT INTO %s (chatid, userid, deadline, warnings) VALUES (?, ?, ?, ?)", turntimersTableName),
+			chatID, userID, time.Now().Add(time.Duration(limit)*time.Minute).Unix(), 0)
+	})
+}
+
+func getTurnTimers() []*turnTimer {
+	timers := make([]*turnTimer, 0)
+	gamedb.MultiQuery(fmt.Sprintf("SELECT chatid, userid, deadline, warnings FROM %s ORDER BY deadline", turntimersTableName),
+		func(rows *sql.Rows) error {
+			timer := &turnTimer{}
+			rows.Scan(&timer.chatid, &timer.userid, &timer.deadline, &timer.warnings)
+			timers = append(timers, timer)
+			return nil
+		})
+	return timers
+}
+
+func updateTurnTimerWarnings(chatID int64, warnings int) error {
+	return gamedb.Exec(fmt.Sprintf("UPDATE %s SET warnings = %d WHERE chatid = %d", turntimersTableName, warnings, chatID))
+}
+
+func clearTurnTimer(chatID int64) error {
+	return gamedb.Exec(fmt.Sprintf("DELETE FROM %s WHERE chatid = %d", turntimersTableName, chatID))
+}
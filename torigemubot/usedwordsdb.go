@@ -3,28 +3,63 @@ package main
 import (
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 )
 
 const usedwordsTableName = "usedwords"
+const currentwordmsgsTableName = "currentwordmsgs"
 
-func addEntry(entry *wordEntry) {
+func addEntry(entry *wordEntry) error {
+	// The first word of a game starts it.
+	gameID := getGameID(entry.chatid)
+	if gameID == 0 {
+		var err error
+		if gameID, err = startGame(entry.chatid); err != nil {
+			return err
+		}
+	}
 	// Use the timestamp seconds for wordindex.
-	gamedb.Exec(fmt.Sprintf("INSERT INTO %s (chatid, userid, wordindex, word, points) VALUES (?, ?, ?, ?, ?)", usedwordsTableName),
-		entry.chatid, entry.userid, time.Now().Unix(), entry.word, entry.points)
-	updatePlayerWords(entry.chatid, entry.userid, 1)
+	if err := gamedb.Exec(fmt.Sprintf("INSERT INTO %s (chatid, userid, wordindex, word, reading, matched, chosen, points, chainbonus, streakbonus, speedbonus, scoring, breakdown, sentat, gameid) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)", usedwordsTableName),
+		entry.chatid, entry.userid, time.Now().Unix(), entry.word, entry.reading, entry.matched, entry.chosen, entry.points, entry.chainBonus, entry.streakBonus, entry.speedBonus, entry.scoring, entry.breakdown, entry.sentAt, gameID); err != nil {
+		return err
+	}
+	return updatePlayerWords(entry.chatid, entry.userid, 1)
 }
 
 func alreadyUsedWord(chatID int64, theWord string) bool {
-	return nil == gamedb.SingleQuery(fmt.Sprintf("SELECT userid FROM %s WHERE chatid = %d and word = '%s'", usedwordsTableName, chatID, theWord))
+	return nil == gamedb.SingleQuery(fmt.Sprintf("SELECT userid FROM %s WHERE chatid = %d AND gameid = %d and word = '%s'", usedwordsTableName, chatID, getGameID(chatID), theWord))
+}
+
+// Remove the readings that earlier words of this game were played with.
+// If every reading has been used, then the earlier word with the last of them is also returned.
+func removeUsedReadings(chatID int64, reading string) (string, *wordEntry) {
+	var earlierEntry *wordEntry
+	history := getWordHistory(chatID)
+	unused := make([]string, 0)
+	for _, r := range strings.Split(reading, ",") {
+		used := false
+		for _, entry := range history {
+			for _, er := range strings.Split(entry.reading, ",") {
+				if er == r {
+					used = true
+					earlierEntry = entry
+				}
+			}
+		}
+		if !used {
+			unused = append(unused, r)
+		}
+	}
+	return strings.Join(unused, ","), earlierEntry
 }
 
 func getFirstEntry(chatID int64) *wordEntry {
 	word := &wordEntry{
 		chatid: chatID,
 	}
-	if nil != gamedb.SingleQuery(fmt.Sprintf("SELECT userid, word, points FROM %s WHERE chatid = %d ORDER BY wordindex ASC LIMIT 1", usedwordsTableName, chatID),
-		&word.userid, &word.word, &word.points) {
+	if nil != gamedb.SingleQuery(fmt.Sprintf("SELECT userid, word, reading, matched, chosen, points, chainbonus, streakbonus, speedbonus, scoring, breakdown, sentat FROM %s WHERE chatid = %d AND gameid = %d ORDER BY wordindex ASC LIMIT 1", usedwordsTableName, chatID, getGameID(chatID)),
+		&word.userid, &word.word, &word.reading, &word.matched, &word.chosen, &word.points, &word.chainBonus, &word.streakBonus, &word.speedBonus, &word.scoring, &word.breakdown, &word.sentAt) {
 		return nil
 	}
 	return word
@@ -34,34 +69,49 @@ func getLastEntry(chatID int64) *wordEntry {
 	word := &wordEntry{
 		chatid: chatID,
 	}
-	if nil != gamedb.SingleQuery(fmt.Sprintf("SELECT userid, word, points FROM %s WHERE chatid = %d ORDER BY wordindex DESC LIMIT 1", usedwordsTableName, chatID),
-		&word.userid, &word.word, &word.points) {
+	if nil != gamedb.SingleQuery(fmt.Sprintf("SELECT userid, word, reading, matched, chosen, points, chainbonus, streakbonus, speedbonus, scoring, breakdown, sentat FROM %s WHERE chatid = %d AND gameid = %d ORDER BY wordindex DESC LIMIT 1", usedwordsTableName, chatID, getGameID(chatID)),
+		&word.userid, &word.word, &word.reading, &word.matched, &word.chosen, &word.points, &word.chainBonus, &word.streakBonus, &word.speedBonus, &word.scoring, &word.breakdown, &word.sentAt) {
 		return nil
 	}
 	return word
 }
 
-func updateFirstEntryPoints(chatID int64, wordsUpdate int) error {
+// Remember the latest message of the bot that shows the current word, so players can reply to it.
+func saveCurrentWordMsg(chatID int64, messageID int, word string) error {
+	return gamedb.Exec(fmt.Sprintf("INSERT OR REPLACE INTO %s (chatid, messageid, word) VALUES (?, ?, ?)", currentwordmsgsTableName), chatID, messageID, word)
+}
+
+func getCurrentWordMsg(chatID int64) (int, string) {
+	var messageID int
+	var word string
+	gamedb.SingleQuery(fmt.Sprintf("SELECT messageid, word FROM %s WHERE chatid = %d", currentwordmsgsTableName, chatID), &messageID, &word)
+	return messageID, word
+}
+
+// The first word is scored once the second word is played, with the scoring policy of that time.
+func updateFirstEntryPoints(chatID int64, wordsUpdate int, scoring string, breakdown string) error {
 	// The following simplified version is not working with the current library, but works with the command-line client.
 	// return gamedb.Exec(fmt.Sprintf("UPDATE %s SET points = %d WHERE chatid = %d ORDER BY wordindex ASC LIMIT 1", usedwordsTableName, wordsUpdate, chatID))
-	return gamedb.Exec(fmt.Sprintf("UPDATE %s SET points = %d WHERE chatid = %d AND wordindex = (SELECT wordindex FROM %s WHERE chatid = %d ORDER BY wordindex ASC LIMIT 1)", usedwordsTableName, wordsUpdate, chatID, usedwordsTableName, chatID))
+	gameID := getGameID(chatID)
+	return gamedb.Exec(fmt.Sprintf("UPDATE %s SET points = ?, scoring = ?, breakdown = ? WHERE chatid = %d AND gameid = %d AND wordindex = (SELECT wordindex FROM %s WHERE chatid = %d AND gameid = %d ORDER BY wordindex ASC LIMIT 1)", usedwordsTableName, chatID, gameID, usedwordsTableName, chatID, gameID),
+		wordsUpdate, scoring, breakdown)
 }
 
 func getWordHistory(chatID int64) wordList {
+	return getGameWords(chatID, getGameID(chatID))
+}
+
+// Get the words of one of the chat's games, in the order that they were played.
+func getGameWords(chatID int64, gameID int64) wordList {
 	words := make(wordList, 0)
-	gamedb.MultiQuery(fmt.Sprintf("SELECT userid, word, points FROM %s WHERE chatid = %d ORDER BY wordindex", usedwordsTableName, chatID),
+	gamedb.MultiQuery(fmt.Sprintf("SELECT userid, word, reading, matched, chosen, points, chainbonus, streakbonus, speedbonus, scoring, breakdown, sentat FROM %s WHERE chatid = %d AND gameid = %d ORDER BY wordindex", usedwordsTableName, chatID, gameID),
 		func(rows *sql.Rows) error {
 			word := &wordEntry{
 				chatid: chatID,
 			}
-			rows.Scan(&word.userid, &word.word, &word.points)
+			rows.Scan(&word.userid, &word.word, &word.reading, &word.matched, &word.chosen, &word.points, &word.chainBonus, &word.streakBonus, &word.speedBonus, &word.scoring, &word.breakdown, &word.sentAt)
 			words = append(words, word)
 			return nil
 		})
 	return words
 }
-
-func clearWordHistory(chatID int64) {
-	// Clear out the word history.
-	gamedb.Exec(fmt.Sprintf("DELETE FROM %s WHERE chatid = %d", usedwordsTableName, chatID))
-}
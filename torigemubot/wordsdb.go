@@ -1,39 +1,126 @@
 package main
 
 import (
+	"database/sql"
 	"fmt"
 	"regexp"
 	"strings"
+	"unicode"
 )
 
 const wordsTablename = "words"
 const customwordsTablename = "customwords"
 const kanjipointsTablename = "kanjipoints"
+const wordtagsTablename = "wordtags"
+const kanjilevelsTablename = "kanjilevels"
+const readingsTablename = "readings"
 const addCustomWordSavePoint = "AddCustomWord"
 const removeCustomWordSavePoint = "RemoveCustomWord"
-const kanaExp = `(\p{Katakana}|\p{Hiragana}|ー)([ゃャょョゅュ])?`
 
-var endKanaExp = regexp.MustCompile(fmt.Sprintf("%s$", kanaExp))
-var beginKanaExp = regexp.MustCompile(fmt.Sprintf("^%s", kanaExp))
 var endsInNExp = regexp.MustCompile(`(ん|ン)$`)
 
-func getWordPts(chatID int64, theWord string, lastEntry *wordEntry) (int, string) {
+// No noun ends with these kana: the particle を, a small っ, and the obsolete ゎ, ゐ and ゑ.
+// In reverse shiritori, a word that begins with one of them can't be continued, so it takes the place of a word that ends in 'ん'.
+const reverseForbiddenStarts = "をっゎゐゑヲッヮヰヱ"
+
+// Small kana that can end a word, mapped to their full size kana.
+var youonLargeMap = map[string]string{
+	"ゃ": "や",
+	"ゅ": "ゆ",
+	"ょ": "よ",
+	"ャ": "ヤ",
+	"ュ": "ユ",
+	"ョ": "ヨ",
+}
+
+// The chat's house rules for chaining a new word onto the last word.
+type chainRules struct {
+	youon       string
+	dakuten     bool
+	longVowel   string
+	chainLength int
+	reverse     bool
+}
+
+func getChainRules(chatID int64) *chainRules {
+	return &chainRules{
+		youon:       getSetting(chatID, settingYouon),
+		dakuten:     getBoolSetting(chatID, settingDakuten),
+		longVowel:   getSetting(chatID, settingLongVowel),
+		chainLength: getIntSetting(chatID, settingChainLength),
+		reverse:     getSetting(chatID, settingMode) == modeReverse,
+	}
+}
+
+// Get the points of the word, and the kana reading(s) it was played with.
+// If the word is not allowed, then the points are zero and the message explains why.
+func getWordPts(chatID int64, theWord string, chosen string, lastEntry *wordEntry) (int, string, string) {
+	rules := getChainRules(chatID)
 	// If points are zero or not found, then return zero. Probably ends in 'n', or not a noun.
 	kana, pts := lookupKana(chatID, theWord)
+	if len(chosen) > 0 && len(kana) > 0 {
+		if kana = chooseReading(kana, chosen); len(kana) == 0 {
+			allKana, _ := lookupKana(chatID, theWord)
+			return 0, "", fmt.Sprintf("その読みはありません: %s（%s）\n読み: %s", theWord, chosen, strings.Replace(allKana, ",", "・", -1))
+		}
+	}
+	if rules.reverse && len(kana) > 0 {
+		// The next word must end with this word's first kana, so a word may not begin with a kana that no word ends with.
+		// Words that end in 'ん' are fine, since the next word only has to match their beginning.
+		if startsWithForbiddenKana(kana) {
+			return 0, "", fmt.Sprintf("言葉は「%s」で始まることが禁止されています: %s「%s」", string([]rune(kana)[0]), theWord, kana)
+		}
+		if pts == 0 && endsInN(kana) {
+			pts = calcWordPoints(theWord)
+		}
+	} else if rules.chainLength > 1 && len(kana) > 0 {
+		// Only the first of the final morae may not be 'ん', so a word may end in 'ん'.
+		if chainStartsWithN(kana, rules) {
+			return 0, "", fmt.Sprintf("最後の%d拍は'ん'で始まることが禁止されています: %s「%s」", rules.chainLength, theWord, kana)
+		}
+		if pts == 0 && endsInN(kana) {
+			pts = calcWordPoints(theWord)
+		}
+	}
+	if minMorae := getIntSetting(chatID, settingMinMorae); pts != 0 && minMorae > 1 {
+		if longest := getLongestReading(kana); countMorae(longest) < minMorae {
+			return 0, "", fmt.Sprintf("言葉は%d拍以上でなければなりません: %s「%s」(%d拍)", minMorae, theWord, strings.Join(splitMorae(longest), "・"), countMorae(longest))
+		}
+	}
+	if genre := getSetting(chatID, settingGenre); pts != 0 && genre != settingOff && !wordHasTag(theWord, genre) {
+		return 0, "", fmt.Sprintf("ジャンル「%s」の言葉ではありません: %s", genre, theWord)
+	}
+	if pts != 0 {
+		if harder := getHarderKanji(chatID, theWord); len(harder) > 0 {
+			return 0, "", fmt.Sprintf("難しすぎる漢字があります: %s「%s」", theWord, strings.Join(harder, "・"))
+		}
+	}
 	if lastEntry != nil && pts != 0 {
 		// Get kana of last word.
-		lastEntryKana, _ := lookupKana(chatID, lastEntry.word)
+		lastEntryKana := getEntryKana(chatID, lastEntry)
+		if rules.reverse {
+			// If ending kana of new word does not match first kana of last word, then return zero.
+			matched, reading := matchReverseKana(lastEntryKana, theWord, kana, rules)
+			if !matched {
+				return 0, "", fmt.Sprintf("終わりの仮名は初めのかなと一致しません: %s「%s」<- %s「%s」\n%s", lastEntry.word, lastEntryKana, theWord, kana, getExpectedKanaDisplay(lastEntryKana, rules))
+			}
+			return pts, reading, ""
+		}
+		chainKana := getChainKana(lastEntry.word, lastEntryKana, rules)
 		// If first kana of new word does not match ending kana of last word, then return zero.
-		if !matchKana(lastEntryKana, kana) {
-			return 0, fmt.Sprintf("初めの仮名は終わりのかなと一致しません: %s「%s」-> %s「%s」", lastEntry.word, lastEntryKana, theWord, kana)
+		matched, reading := matchKana(chainKana, kana, rules)
+		if !matched {
+			return 0, "", fmt.Sprintf("初めの仮名は終わりのかなと一致しません: %s「%s」-> %s「%s」\n%s", lastEntry.word, lastEntryKana, theWord, kana, getExpectedKanaDisplay(chainKana, rules))
 		}
+		return pts, reading, ""
 	} else if pts == 0 {
 		if endsInN(kana) {
-			return pts, fmt.Sprintf("言葉は'ん'が終わることが禁止されています: %s", theWord)
+			return pts, "", fmt.Sprintf("言葉は'ん'が終わることが禁止されています: %s", theWord)
 		}
-		return pts, fmt.Sprintf("無効言葉: %s", theWord)
+		return pts, "", fmt.Sprintf("無効言葉: %s", theWord)
 	}
-	return pts, ""
+	// The first word can be played with any of its readings.
+	return pts, kana, ""
 }
 
 func lookupKana(chatID int64, theWord string) (string, int) {
@@ -42,6 +129,14 @@ func lookupKana(chatID int64, theWord string) (string, int) {
 		var customkana string
 		found, customkana, pts = lookupCustomKana(chatID, theWord)
 		if !found {
+			if matches := lookupKanaWords(theWord); len(matches) > 0 {
+				// Like the words of kana only in the dictionary, it is worth the kana word points, unless it ends in 'ん'.
+				kana = expandLongVowels(toHiragana(theWord))
+				if endsInN(kana) {
+					return kana, 0
+				}
+				return kana, calcWordPoints(theWord)
+			}
 			return kana, 0
 		}
 		kana = customkana
@@ -49,6 +144,28 @@ func lookupKana(chatID int64, theWord string) (string, int) {
 	return kana, pts
 }
 
+// Get the kana of a played word. A word that was played with a chosen reading only chains from that reading.
+func getEntryKana(chatID int64, entry *wordEntry) string {
+	kana, _ := lookupKana(chatID, entry.word)
+	if len(entry.chosen) > 0 {
+		if chosenKana := chooseReading(kana, entry.chosen); len(chosenKana) > 0 {
+			return chosenKana
+		}
+	}
+	return kana
+}
+
+// Narrow the kana down to the chosen reading (i.e., 上手 is read じょうず or うわて), or "" if the word has no such reading.
+func chooseReading(kana string, chosen string) string {
+	chosenKana := expandLongVowels(toHiragana(chosen))
+	for _, k := range strings.Split(kana, ",") {
+		if k == chosenKana {
+			return k
+		}
+	}
+	return ""
+}
+
 func lookupStandardKana(theWord string) (bool, string, int) {
 	var kana string
 	var pts int
@@ -56,6 +173,107 @@ func lookupStandardKana(theWord string) (bool, string, int) {
 	return found, kana, pts
 }
 
+// Find the words that a word of kana only could be (i.e., はし → 橋, 箸).
+func lookupKanaWords(theWord string) []string {
+	words := make([]string, 0)
+	if !isKanaWord(theWord) {
+		return words
+	}
+	gamedb.MultiQuery(fmt.Sprintf("SELECT kanji FROM %s WHERE reading = '%s' ORDER BY kanji", readingsTablename, expandLongVowels(toHiragana(theWord))),
+		func(rows *sql.Rows) error {
+			var word string
+			rows.Scan(&word)
+			words = append(words, word)
+			return nil
+		})
+	return words
+}
+
+// Get the words that a submission of kana only was matched to, or "" if the submission is a word of its own.
+func getMatchedWords(chatID int64, theWord string) string {
+	if found, _, pts := lookupStandardKana(theWord); found && pts != 0 {
+		return ""
+	}
+	if found, _, _ := lookupCustomKana(chatID, theWord); found {
+		return ""
+	}
+	matches := make([]string, 0)
+	for _, word := range lookupKanaWords(theWord) {
+		if word != theWord {
+			matches = append(matches, word)
+		}
+	}
+	return strings.Join(matches, ",")
+}
+
+// The reading keeps the 'ー' long vowel mark that the kana replaces with a vowel.
+func lookupReading(theWord string) (bool, string) {
+	var reading string
+	found := nil == gamedb.SingleQuery(fmt.Sprintf("SELECT reading FROM %s WHERE kanji = '%s'", wordsTablename, theWord), &reading)
+	return found, reading
+}
+
+func wordHasTag(theWord string, tag string) bool {
+	if nil == gamedb.SingleQuery(fmt.Sprintf("SELECT kanji FROM %s WHERE kanji = '%s' AND tag = '%s'", wordtagsTablename, theWord, tag)) {
+		return true
+	}
+	// A word of kana only has the tag if any of the words it could be has it.
+	for _, word := range lookupKanaWords(theWord) {
+		if word != theWord && wordHasTag(word, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// Get the frequency rank of the word, from 1 (most common) to 48, or 0 if it is in none of the frequency lists.
+// A word of kana only has the rank of the most common word it could be.
+// Custom words, and words from an older dictionary, are not found.
+func lookupFreqRank(theWord string) (bool, int) {
+	found := false
+	rank := 0
+	words := append([]string{theWord}, lookupKanaWords(theWord)...)
+	for _, word := range words {
+		var r int
+		if nil == gamedb.SingleQuery(fmt.Sprintf("SELECT freq FROM %s WHERE kanji = '%s'", wordsTablename, word), &r) {
+			if !found || (r != 0 && (rank == 0 || r < rank)) {
+				rank = r
+			}
+			found = true
+		}
+	}
+	return found, rank
+}
+
+// Get the tags of the given kind (field or misc) of the word. A word of kana only has the tags of the words it could be.
+func getWordTags(theWord string, kind string) []string {
+	tags := make([]string, 0)
+	words := append([]string{theWord}, lookupKanaWords(theWord)...)
+	for _, word := range words {
+		gamedb.MultiQuery(fmt.Sprintf("SELECT tag FROM %s WHERE kanji = '%s' AND kind = '%s'", wordtagsTablename, word, kind),
+			func(rows *sql.Rows) error {
+				var tag string
+				rows.Scan(&tag)
+				tags = append(tags, tag)
+				return nil
+			})
+	}
+	return tags
+}
+
+// Get the field tags that can be used as a genre, and how many words have each one.
+func getGenres() []*genreEntry {
+	genres := make([]*genreEntry, 0)
+	gamedb.MultiQuery(fmt.Sprintf("SELECT tag, COUNT(*) FROM %s WHERE kind = 'field' GROUP BY tag ORDER BY COUNT(*) DESC", wordtagsTablename),
+		func(rows *sql.Rows) error {
+			genre := &genreEntry{}
+			rows.Scan(&genre.tag, &genre.numWords)
+			genres = append(genres, genre)
+			return nil
+		})
+	return genres
+}
+
 func lookupCustomKana(chatID int64, theWord string) (bool, string, int) {
 	var kana string
 	var pts int
@@ -63,13 +281,91 @@ func lookupCustomKana(chatID int64, theWord string) (bool, string, int) {
 	return found, kana, pts
 }
 
-func matchKana(lastWordKana string, newWordKana string) bool {
+// Get the kana of the last word that the next word chains from, following the chat's long vowel rule.
+func getChainKana(lastWord string, lastWordKana string, rules *chainRules) string {
+	if rules.longVowel == longVowelVowel {
+		// The kana already ends in the vowel that the 'ー' extends.
+		return lastWordKana
+	}
+	chainKana := make([]string, 0)
+	for _, r := range getLongVowelReadings(lastWord, lastWordKana) {
+		chainKana = append(chainKana, getChainForms(r, rules)...)
+	}
+	return strings.Join(chainKana, ",")
+}
+
+// Get the readings of the word that still have their 'ー' long vowel marks.
+// Only the readings of the kana are kept, so a chosen reading stays chosen.
+func getLongVowelReadings(theWord string, kana string) []string {
+	if isKanaWord(theWord) {
+		// A word of kana only is its own reading.
+		return []string{toHiragana(theWord)}
+	}
+	found, reading := lookupReading(theWord)
+	if !found {
+		// Custom words, and words from an older dictionary, have no long vowel reading.
+		return strings.Split(kana, ",")
+	}
+	readings := make([]string, 0)
+	for _, r := range strings.Split(reading, ",") {
+		if len(chooseReading(kana, r)) > 0 {
+			readings = append(readings, r)
+		}
+	}
+	if len(readings) == 0 {
+		// The readings don't line up with the kana, so just use the kana.
+		return strings.Split(kana, ",")
+	}
+	return readings
+}
+
+// Get the forms of the reading that the next word can chain from, following the chat's long vowel rule.
+func getChainForms(reading string, rules *chainRules) []string {
+	vowel := expandLongVowels(reading)
+	// Only the final 'ー' is ignored. Any others are still vowels.
+	ignored := expandLongVowels(strings.TrimRight(reading, "ー"))
+	switch {
+	case rules.longVowel == longVowelIgnore:
+		return []string{ignored}
+	case rules.longVowel == longVowelEither && ignored != vowel:
+		return []string{vowel, ignored}
+	}
+	return []string{vowel}
+}
+
+// Check whether the new word can follow the last word, and get the reading(s) of the new word that can.
+func matchKana(lastWordKana string, newWordKana string, rules *chainRules) (bool, string) {
 	lastKana := strings.Split(lastWordKana, ",")
 	newKana := strings.Split(newWordKana, ",")
-	for _, lk := range lastKana {
-		endingMatch := endKanaExp.FindStringSubmatch(lk)
-		for _, nk := range newKana {
-			if endAndBeginMatch(endingMatch, beginKanaExp.FindStringSubmatch(nk)) {
+	matched := make([]string, 0)
+	for _, nk := range newKana {
+		for _, lk := range lastKana {
+			if endAndBeginMatch(getEndingMorae(lk, rules), splitMorae(nk), rules) {
+				matched = append(matched, nk)
+				break
+			}
+		}
+	}
+	return len(matched) > 0, strings.Join(matched, ",")
+}
+
+// In reverse shiritori (頭取り), the new word comes before the last word in the chain.
+// Check whether the ending of the new word matches the beginning of the last word, and get the reading(s) of the new word that do.
+func matchReverseKana(lastWordKana string, newWord string, newWordKana string, rules *chainRules) (bool, string) {
+	lastKana := strings.Split(lastWordKana, ",")
+	matched := make([]string, 0)
+	for _, nr := range getLongVowelReadings(newWord, newWordKana) {
+		if matchAnyKana(getChainForms(nr, rules), lastKana, rules) {
+			matched = append(matched, expandLongVowels(nr))
+		}
+	}
+	return len(matched) > 0, strings.Join(matched, ",")
+}
+
+func matchAnyKana(endingKana []string, beginningKana []string, rules *chainRules) bool {
+	for _, ek := range endingKana {
+		for _, bk := range beginningKana {
+			if endAndBeginMatch(getEndingMorae(ek, rules), splitMorae(bk), rules) {
 				return true
 			}
 		}
@@ -77,22 +373,137 @@ func matchKana(lastWordKana string, newWordKana string) bool {
 	return false
 }
 
-func endAndBeginMatch(endingMatch []string, beginningMatch []string) bool {
+// Get the first morae of a word that the new word must end with in reverse shiritori.
+func getBeginningMorae(kana string, rules *chainRules) []string {
+	morae := splitMorae(kana)
+	if len(morae) > rules.chainLength {
+		morae = morae[:rules.chainLength]
+	}
+	return morae
+}
+
+// Check whether every reading begins with a kana that no word ends with, so the reverse chain can't continue.
+func startsWithForbiddenKana(kana string) bool {
+	for _, k := range strings.Split(kana, ",") {
+		if len(k) == 0 || !strings.ContainsRune(reverseForbiddenStarts, []rune(k)[0]) {
+			return false
+		}
+	}
+	return true
+}
+
+// Get the final morae of a word that the next word must begin with.
+func getEndingMorae(kana string, rules *chainRules) []string {
+	morae := splitMorae(kana)
+	if last := len(morae) - 1; rules.youon == youonLarge && last >= 0 {
+		// The house rule is to continue with the full size kana instead (i.e., しゃ → や).
+		kana := []rune(morae[last])
+		if large, ok := youonLargeMap[string(kana[1:])]; ok {
+			morae = append(morae[:last], string(kana[:1]), large)
+		}
+	}
+	if len(morae) > rules.chainLength {
+		morae = morae[len(morae)-rules.chainLength:]
+	}
+	return morae
+}
+
+func endAndBeginMatch(endingMorae []string, beginningMorae []string, rules *chainRules) bool {
+	if len(endingMorae) == 0 || len(beginningMorae) < len(endingMorae) {
+		return false
+	}
+	last := len(endingMorae) - 1
+	for index := 0; index < last; index++ {
+		if !sameMora(endingMorae[index], beginningMorae[index], rules) {
+			return false
+		}
+	}
 	// If the word ends in a combined phonic (i.e., しゃ), then the
 	// next word must begin with that same combination.
 	// However, if the word ends in just し, then the next word can
 	// optionally start with combined phonic (i.e., しゃ) or just し.
-	if len(beginningMatch) < len(endingMatch) {
+	beginning := beginningMorae[last]
+	if len([]rune(endingMorae[last])) == 1 {
+		beginning = string([]rune(beginning)[0])
+	}
+	return sameMora(endingMorae[last], beginning, rules)
+}
+
+func sameMora(endingMora string, beginningMora string, rules *chainRules) bool {
+	endingKana := []rune(endingMora)
+	beginningKana := []rune(beginningMora)
+	if len(endingKana) != len(beginningKana) {
 		return false
 	}
-	for index := 1; index < len(endingMatch); index++ {
-		if len(endingMatch[index]) > 0 && endingMatch[index] != beginningMatch[index] {
+	for index := range endingKana {
+		if !sameKana(string(endingKana[index]), string(beginningKana[index]), rules) {
+			return false
+		}
+	}
+	return true
+}
+
+func sameKana(endingKana string, beginningKana string, rules *chainRules) bool {
+	if rules.dakuten {
+		// Voiced, semi-voiced and plain kana are all the same kana (i.e., ぷ = ぶ = ふ).
+		return plainKana(endingKana) == plainKana(beginningKana)
+	}
+	return endingKana == beginningKana
+}
+
+// Check whether the morae the next word must begin with start with 'ん' for every reading.
+func chainStartsWithN(kana string, rules *chainRules) bool {
+	for _, k := range strings.Split(kana, ",") {
+		endingMorae := getEndingMorae(k, rules)
+		if len(endingMorae) == 0 || !endsInNExp.MatchString(endingMorae[0]) {
 			return false
 		}
 	}
 	return true
 }
 
+// Describe which morae the next word is allowed to begin with, or end with in reverse shiritori.
+func getExpectedKanaDisplay(lastWordKana string, rules *chainRules) string {
+	expected := make([]string, 0)
+	for _, lk := range strings.Split(lastWordKana, ",") {
+		endingMorae := getEndingMorae(lk, rules)
+		if rules.reverse {
+			endingMorae = getBeginningMorae(lk, rules)
+		}
+		if len(endingMorae) == 0 {
+			continue
+		}
+		if rules.dakuten && len(endingMorae) == 1 {
+			kana := []rune(endingMorae[0])
+			youon := string(kana[1:])
+			expected = append(expected, fmt.Sprintf("「%s%s」", strings.Join(dakutenVariants(string(kana[0])), youon+"・"), youon))
+		} else if len(endingMorae) > 1 {
+			expected = append(expected, fmt.Sprintf("「%s」(%s)", strings.Join(endingMorae, ""), strings.Join(endingMorae, "・")))
+		} else {
+			expected = append(expected, fmt.Sprintf("「%s」", endingMorae[0]))
+		}
+	}
+	position := "始まる"
+	if rules.reverse {
+		position = "終わる"
+	}
+	display := fmt.Sprintf("次の言葉は%sで%s必要があります。", strings.Join(expected, "か"), position)
+	if rules.dakuten {
+		display += "(濁点・半濁点は無視されます)"
+	}
+	return display
+}
+
+func getLongestReading(kana string) string {
+	longest := ""
+	for _, k := range strings.Split(kana, ",") {
+		if countMorae(k) > countMorae(longest) {
+			longest = k
+		}
+	}
+	return longest
+}
+
 func endsInN(kana string) bool {
 	// Check for ending in ん.
 	for _, k := range strings.Split(kana, ",") {
@@ -126,17 +537,52 @@ func getKanjiPoints(kanjiCharacter string) int {
 	return pts
 }
 
+// Get the kanji of the word that are harder than the chat's JLPT level or school grade allows.
+func getHarderKanji(chatID int64, kanji string) []string {
+	harder := make([]string, 0)
+	maxJLPT := getSetting(chatID, settingJLPT)
+	maxGrade := getSetting(chatID, settingGrade)
+	if maxJLPT == settingOff && maxGrade == settingOff {
+		return harder
+	}
+	for _, k := range kanji {
+		if !isKanji(k) {
+			continue
+		}
+		found, jlpt, grade := lookupKanjiLevel(string(k))
+		// Kanji without a level are harder than any level.
+		if !found ||
+			(maxJLPT != settingOff && (jlpt == 0 || jlpt < getIntSetting(chatID, settingJLPT))) ||
+			(maxGrade != settingOff && (grade == 0 || grade > getIntSetting(chatID, settingGrade))) {
+			harder = append(harder, string(k))
+		}
+	}
+	return harder
+}
+
+func isKanji(k rune) bool {
+	// The 々 iteration mark is not a kanji of its own.
+	return unicode.Is(unicode.Han, k) && k != '々'
+}
+
+func lookupKanjiLevel(kanjiCharacter string) (bool, int, int) {
+	var jlpt, grade int
+	found := nil == gamedb.SingleQuery(fmt.Sprintf("SELECT jlpt, grade FROM %s WHERE kanji = '%s'", kanjilevelsTablename, kanjiCharacter), &jlpt, &grade)
+	return found, jlpt, grade
+}
+
 func addCustomWord(chatID int64, userID int64, kanji string, kana string) error {
 	wordpts := calcWordPoints(kanji)
+	addpts := getIntSetting(chatID, settingAddPts)
 	// Replace any existing custom word with the updated version of it.
 	return gamedb.ExecWithSavePoint(addCustomWordSavePoint, func() error {
 		if err := removeCustomWord(chatID, kanji); err != nil {
 			return err
 		}
-		if err := gamedb.Exec(fmt.Sprintf("INSERT INTO %s (chatid, userid, kanji, kana, points) VALUES (?, ?, ?, ?, ?)", customwordsTablename), chatID, userID, kanji, kana, wordpts); err != nil {
+		if err := gamedb.Exec(fmt.Sprintf("INSERT INTO %s (chatid, userid, kanji, kana, points, addpts) VALUES (?, ?, ?, ?, ?, ?)", customwordsTablename), chatID, userID, kanji, kana, wordpts, addpts); err != nil {
 			return err
 		}
-		return updatePlayerScore(chatID, userID, addWordPts)
+		return updatePlayerScore(chatID, userID, addpts, scoreReasonAddWord, kanji)
 	})
 }
 
@@ -146,11 +592,16 @@ func removeCustomWord(chatID int64, kanji string) error {
 		// Custom word does not exist, so it has been removed.
 		return nil
 	}
+	// Take back what was awarded for adding the word, even if the setting has changed since.
+	var addpts int
+	if err := gamedb.SingleQuery(fmt.Sprintf("SELECT addpts FROM %s WHERE chatid = %d AND kanji = '%s'", customwordsTablename, chatID, kanji), &addpts); err != nil {
+		return err
+	}
 	return gamedb.ExecWithSavePoint(removeCustomWordSavePoint, func() error {
 		if err := gamedb.Exec(fmt.Sprintf("DELETE FROM %s WHERE chatid = %d AND kanji = '%s'", customwordsTablename, chatID, kanji)); err != nil {
 			return err
 		}
-		return updatePlayerScore(chatID, userID, -addWordPts)
+		return updatePlayerScore(chatID, userID, -addpts, scoreReasonRemoveWord, kanji)
 	})
 }
 
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGetEndingMorae(t *testing.T) {
+	tests := []struct {
+		kana        string
+		youon       string
+		chainLength int
+		morae       []string
+	}{
+		{"すし", youonCombo, 1, []string{"し"}},
+		{"じてんしゃ", youonCombo, 1, []string{"しゃ"}},
+		{"じてんしゃ", youonLarge, 1, []string{"や"}},
+		{"じてんしゃ", youonCombo, 2, []string{"ん", "しゃ"}},
+		{"じてんしゃ", youonLarge, 2, []string{"し", "や"}},
+		{"がっこう", youonCombo, 2, []string{"こ", "う"}},
+		{"らーめん", youonCombo, 2, []string{"め", "ん"}},
+		{"き", youonCombo, 2, []string{"き"}},
+	}
+	for _, test := range tests {
+		rules := &chainRules{youon: test.youon, chainLength: test.chainLength}
+		if morae := getEndingMorae(test.kana, rules); !reflect.DeepEqual(morae, test.morae) {
+			t.Errorf("getEndingMorae(%q, %s, %d) = %q; want %q", test.kana, test.youon, test.chainLength, morae, test.morae)
+		}
+	}
+}
+
+func TestEndAndBeginMatch(t *testing.T) {
+	tests := []struct {
+		ending    []string
+		beginning string
+		dakuten   bool
+		want      bool
+	}{
+		{[]string{"り"}, "りす", false, true},
+		{[]string{"り"}, "すし", false, false},
+		// A plain kana may be followed by its combination, but not the other way around.
+		{[]string{"し"}, "しゃこ", false, true},
+		{[]string{"しゃ"}, "しゃこ", false, true},
+		{[]string{"しゃ"}, "しか", false, false},
+		// Voiced and semi-voiced kana match their plain kana when dakuten is ignored.
+		{[]string{"ぷ"}, "ふろ", false, false},
+		{[]string{"ぷ"}, "ふろ", true, true},
+		{[]string{"さ"}, "ざる", true, true},
+		{[]string{"と"}, "ちず", true, false},
+		// Two kana chaining.
+		{[]string{"こ", "う"}, "こうし", false, true},
+		{[]string{"こ", "う"}, "こおり", false, false},
+		{[]string{"こ", "う"}, "こ", false, false},
+		{[]string{}, "すし", false, false},
+	}
+	for _, test := range tests {
+		rules := &chainRules{youon: youonCombo, dakuten: test.dakuten, chainLength: len(test.ending)}
+		if got := endAndBeginMatch(test.ending, splitMorae(test.beginning), rules); got != test.want {
+			t.Errorf("endAndBeginMatch(%q, %q, dakuten %v) = %v; want %v", test.ending, test.beginning, test.dakuten, got, test.want)
+		}
+	}
+}
+
+func TestGetChainForms(t *testing.T) {
+	tests := []struct {
+		reading   string
+		longVowel string
+		forms     []string
+	}{
+		{"みきさー", longVowelVowel, []string{"みきさあ"}},
+		{"みきさー", longVowelIgnore, []string{"みきさ"}},
+		{"みきさー", longVowelEither, []string{"みきさあ", "みきさ"}},
+		// Only the final 'ー' is ignored.
+		{"こーひー", longVowelIgnore, []string{"こおひ"}},
+		{"すし", longVowelEither, []string{"すし"}},
+	}
+	for _, test := range tests {
+		rules := &chainRules{longVowel: test.longVowel}
+		if forms := getChainForms(test.reading, rules); !reflect.DeepEqual(forms, test.forms) {
+			t.Errorf("getChainForms(%q, %s) = %q; want %q", test.reading, test.longVowel, forms, test.forms)
+		}
+	}
+}
+
+func TestMatchReverseKana(t *testing.T) {
+	tests := []struct {
+		lastKana  string
+		newWord   string
+		longVowel string
+		matched   bool
+		reading   string
+	}{
+		{"りす", "とり", longVowelVowel, true, "とり"},
+		{"りす", "すし", longVowelVowel, false, ""},
+		{"あき", "ミキサー", longVowelVowel, true, "みきさあ"},
+		{"あき", "ミキサー", longVowelIgnore, false, ""},
+		{"さくら", "ミキサー", longVowelIgnore, true, "みきさあ"},
+		{"さくら", "ミキサー", longVowelEither, true, "みきさあ"},
+		{"しゃこ", "いしゃ", longVowelVowel, true, "いしゃ"},
+		{"しか", "いしゃ", longVowelVowel, false, ""},
+	}
+	for _, test := range tests {
+		rules := &chainRules{youon: youonCombo, longVowel: test.longVowel, chainLength: 1, reverse: true}
+		matched, reading := matchReverseKana(test.lastKana, test.newWord, toHiragana(test.newWord), rules)
+		if matched != test.matched || reading != test.reading {
+			t.Errorf("matchReverseKana(%q, %q, %s) = %v, %q; want %v, %q", test.lastKana, test.newWord, test.longVowel, matched, reading, test.matched, test.reading)
+		}
+	}
+}
+
+func TestChainStartsWithN(t *testing.T) {
+	tests := []struct {
+		kana        string
+		chainLength int
+		want        bool
+	}{
+		{"みかん", 1, true},
+		{"みかん", 2, false},
+		{"ほんや", 2, true},
+		// Every reading must start its chain with 'ん'.
+		{"ほんや,ほんだな", 2, false},
+		{"すし", 2, false},
+	}
+	for _, test := range tests {
+		rules := &chainRules{youon: youonCombo, chainLength: test.chainLength}
+		if got := chainStartsWithN(test.kana, rules); got != test.want {
+			t.Errorf("chainStartsWithN(%q, %d) = %v; want %v", test.kana, test.chainLength, got, test.want)
+		}
+	}
+}
+
+func TestStartsWithForbiddenKana(t *testing.T) {
+	tests := []struct {
+		kana string
+		want bool
+	}{
+		{"をとこ", true},
+		{"ヲタク", true},
+		{"みかん", false},
+		{"をとこ,おとこ", false},
+	}
+	for _, test := range tests {
+		if got := startsWithForbiddenKana(test.kana); got != test.want {
+			t.Errorf("startsWithForbiddenKana(%q) = %v; want %v", test.kana, got, test.want)
+		}
+	}
+}